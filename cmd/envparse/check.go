@@ -0,0 +1,122 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// runCheck validates every file against a schema and reports each violation.
+// Returns exitFail if any file could not be parsed or had a violation.
+func runCheck(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: envparse check [flags] [file ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reports every key in the given files which is missing, undeclared, or")
+		fmt.Fprintln(stderr, "invalid according to the annotated schema file.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	pf := parseFlags{}
+	pf.register(fs)
+	schemaFile := fs.String("schema", ".env.example", "annotated example file declaring the allowed keys")
+	allowUnknown := fs.Bool("allow-unknown", false, "allow keys which are not declared in the schema")
+	jsonOut := fs.Bool("json", false, "write diagnostics as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	opts, err := pf.options()
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitUsage
+	}
+
+	schema, err := readSchema(*schemaFile, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitFail
+	}
+	schema.AllowUnknown = *allowUnknown
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	diags := []diagnostic{}
+	for _, name := range files {
+		diags = append(diags, checkFile(name, stdin, schema, opts)...)
+	}
+
+	if err := writeDiagnostics(stdout, diags, *jsonOut); err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitFail
+	}
+
+	if len(diags) > 0 {
+		return exitFail
+	}
+	return exitOK
+}
+
+// readSchema parses the named schema file.
+func readSchema(name string, opts []envparse.Option) (*envparse.Schema, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	opts = append(opts[:len(opts):len(opts)], envparse.WithSource(name))
+	return envparse.ParseSchema(f, opts...)
+}
+
+// checkFile parses the named file and returns a diagnostic for every parse
+// error or violation of the schema.
+func checkFile(name string, stdin io.Reader, schema *envparse.Schema, opts []envparse.Option) []diagnostic {
+	src := envparse.FileSource(name)
+	if name == "-" {
+		src = envparse.ReaderSource(name, stdin)
+	}
+
+	opts = append(opts[:len(opts):len(opts)], envparse.WithEmptyValues(), envparse.WithCollectErrors())
+	entries, err := envparse.NewLoader(opts...).Add(src).Load()
+
+	diags := []diagnostic{}
+	var perrs envparse.ParseErrors
+	switch {
+	case err == nil:
+	case errors.As(err, &perrs):
+		for _, perr := range perrs {
+			diags = append(diags, parseDiagnostic(name, perr))
+		}
+		return diags
+	default:
+		return append(diags, diagnostic{File: name, Severity: "error", Message: err.Error()})
+	}
+
+	for _, v := range envparse.Validate(entries, schema) {
+		diags = append(diags, diagnostic{
+			File:     name,
+			Line:     v.Line,
+			Key:      v.Key,
+			Severity: "error",
+			Message:  fmt.Sprintf("%s: %v", v.Key, v.Err),
+		})
+	}
+	return diags
+}
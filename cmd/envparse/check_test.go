@@ -0,0 +1,70 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envparse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	schema := filepath.Join(dir, ".env.example")
+	buf := "# @type int @default 8080\nPORT=\nLOG_LEVEL=info # @optional @enum debug,info\nAPI_TOKEN=changeme\n"
+	if err := ioutil.WriteFile(schema, []byte(buf), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := filepath.Join(dir, ".env.bad")
+	if err := ioutil.WriteFile(bad, []byte("# @nope\nA=1\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		in   string
+		out  string
+		code int
+	}{
+		{"Valid", nil, "API_TOKEN=x\nLOG_LEVEL=debug\n", "", exitOK},
+		{"Missing", nil, "PORT=80\n", "-: API_TOKEN: missing required key\n", exitFail},
+		{"Invalid", nil, "API_TOKEN=x\nPORT=http\n", "-:2: PORT: invalid value: not a valid int\n", exitFail},
+		{"Unknown", nil, "API_TOKEN=x\nDEBUG=1\n", "-:2: DEBUG: unknown key\n", exitFail},
+		{"AllowUnknown", []string{"-allow-unknown"}, "API_TOKEN=x\nDEBUG=1\n", "", exitOK},
+		{"ParseError", nil, "API_TOKEN\n", "-:1:1: missing =\n", exitFail},
+		{"MissingFile", []string{"does-not-exist.env"}, "", "does-not-exist.env: open does-not-exist.env: no such file or directory\n", exitFail},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			args := append([]string{"check", "-schema", schema}, c.args...)
+			code := run(args, strings.NewReader(c.in), &stdout, &stderr)
+			if code != c.code {
+				t.Errorf("expected exit code %d but found %d: %s", c.code, code, stderr.String())
+			}
+			if stdout.String() != c.out {
+				t.Errorf("expected output:\n%s\nbut found:\n%s", c.out, stdout.String())
+			}
+		})
+	}
+
+	// Errors in the schema are reported and fail without checking any files
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	if code := run([]string{"check", "-schema", bad}, strings.NewReader("A=1\n"), &stdout, &stderr); code != exitFail {
+		t.Errorf("expected exit code %d but found %d", exitFail, code)
+	}
+	if !strings.Contains(stderr.String(), "error on line 1: unknown annotation @nope") {
+		t.Errorf("unexpected error: %s", stderr.String())
+	}
+}
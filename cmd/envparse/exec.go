@@ -0,0 +1,160 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// Exit codes matching env(1) when the command cannot be run.
+const (
+	exitCannotRun = 126
+	exitNotFound  = 127
+)
+
+// stringsFlag is a flag which may be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// execve replaces the current process with the program at path. Overridden
+// by tests.
+var execve = execProcess
+
+// runExec loads the variables from every file and replaces the current
+// process with the command.
+func runExec(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: envparse exec [flags] [KEY=value ...] [--] command [arg ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Runs command with the variables from each file applied in order, followed")
+		fmt.Fprintln(stderr, "by any KEY=value arguments.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	pf := parseFlags{}
+	pf.register(fs)
+	files := stringsFlag{}
+	fs.Var(&files, "f", "file to load, may be repeated (default .env)")
+	clearEnv := fs.Bool("clear", false, "do not inherit the environment of envparse")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	opts, err := pf.options()
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitUsage
+	}
+	if len(files) == 0 {
+		files = stringsFlag{".env"}
+	}
+
+	// Leading KEY=value arguments override the files
+	rest := fs.Args()
+	overrides := []envparse.Pair{}
+	for len(rest) > 0 {
+		eq := strings.IndexByte(rest[0], '=')
+		if eq < 0 {
+			break
+		}
+		if eq == 0 {
+			fmt.Fprintf(stderr, "envparse: invalid override %q: empty key\n", rest[0])
+			return exitUsage
+		}
+		overrides = append(overrides, envparse.Pair{Key: rest[0][:eq], Val: rest[0][eq+1:]})
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && rest[0] == "--" {
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		fmt.Fprintln(stderr, "envparse: missing command")
+		fs.Usage()
+		return exitUsage
+	}
+
+	pairs, err := envparse.ParseFiles(files, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitFail
+	}
+
+	env := []string{}
+	if !*clearEnv {
+		env = os.Environ()
+	}
+	env = envparse.Environ(env, append(pairs, overrides...))
+
+	path, err := lookPath(rest[0], env)
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitNotFound
+	}
+
+	code, err := execve(path, rest, env)
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitCannotRun
+	}
+	return code
+}
+
+// lookPath finds the program named file like exec.LookPath, but searches the
+// PATH in env, which the program will run with, instead of the PATH of this
+// process. The default search path is used if env has no PATH.
+func lookPath(file string, env []string) (string, error) {
+	if strings.ContainsRune(file, '/') || strings.ContainsRune(file, filepath.Separator) {
+		return exec.LookPath(file)
+	}
+
+	path, found := "", false
+	for _, kv := range env {
+		k, v := kv, ""
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			k, v = kv[:i], kv[i+1:]
+		}
+		if k == "PATH" || (runtime.GOOS == "windows" && strings.EqualFold(k, "PATH")) {
+			path, found = v, true
+		}
+	}
+	if !found {
+		path = defaultPath()
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		// Empty entries are the current directory, which must be explicit or
+		// exec.LookPath would search this process's PATH
+		name := filepath.Join(dir, file)
+		if !strings.ContainsRune(name, filepath.Separator) {
+			name = "." + string(filepath.Separator) + name
+		}
+		if p, err := exec.LookPath(name); err == nil {
+			return p, nil
+		}
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
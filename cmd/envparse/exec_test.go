@@ -0,0 +1,132 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envparse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	a, b := filepath.Join(dir, "a.env"), filepath.Join(dir, "b.env")
+	if err := ioutil.WriteFile(a, []byte("A=1\nB=1\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(b, []byte("B=2\nC=2\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var argv, env []string
+	execve = func(path string, a, e []string) (int, error) {
+		argv, env = a, e
+		return 3, nil
+	}
+	defer func() { execve = execProcess }()
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := run([]string{"exec", "-clear", "-f", a, "-f", b, "C=3", "--", "true", "x"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 3 {
+		t.Errorf("expected exit code 3 but found %d: %s", code, stderr.String())
+	}
+	if expected := []string{"true", "x"}; !reflect.DeepEqual(argv, expected) {
+		t.Errorf("expected args %v but found %v", expected, argv)
+	}
+	if expected := []string{"A=1", "B=2", "C=3"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected env %v but found %v", expected, env)
+	}
+
+	// The environment is inherited by default
+	os.Setenv("ENVPARSE_TEST", "1")
+	defer os.Unsetenv("ENVPARSE_TEST")
+	if code := run([]string{"exec", "-f", a, "true"}, strings.NewReader(""), &stdout, &stderr); code != 3 {
+		t.Errorf("expected exit code 3 but found %d: %s", code, stderr.String())
+	}
+	found := false
+	for _, kv := range env {
+		found = found || kv == "ENVPARSE_TEST=1"
+	}
+	if !found {
+		t.Errorf("expected ENVPARSE_TEST=1 in %v", env)
+	}
+}
+
+func TestExec_Path(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires an executable shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "envparse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	prog := filepath.Join(dir, "envparse-test-prog")
+	if err := ioutil.WriteFile(prog, []byte("#!/bin/sh\n"), 0o700); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var path string
+	execve = func(p string, a, e []string) (int, error) {
+		path = p
+		return 0, nil
+	}
+	defer func() { execve = execProcess }()
+
+	// The program is found using the PATH it will run with
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	args := []string{"exec", "-clear", "-f", os.DevNull, "PATH=" + dir, "envparse-test-prog"}
+	if code := run(args, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Errorf("expected exit code %d but found %d: %s", exitOK, code, stderr.String())
+	}
+	if path != prog {
+		t.Errorf("expected %s but found %s", prog, path)
+	}
+
+	// Programs on this process's PATH are not found if the PATH differs
+	args = []string{"exec", "-f", os.DevNull, "PATH=" + dir, "sh"}
+	if code := run(args, strings.NewReader(""), &stdout, &stderr); code != exitNotFound {
+		t.Errorf("expected exit code %d but found %d", exitNotFound, code)
+	}
+}
+
+func TestExec_Err(t *testing.T) {
+	execve = func(path string, a, e []string) (int, error) {
+		t.Fatalf("unexpected exec of %s", path)
+		return 0, nil
+	}
+	defer func() { execve = execProcess }()
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"MissingCommand", []string{"-f", os.DevNull, "A=1"}, exitUsage},
+		{"EmptyOverride", []string{"-f", os.DevNull, "=1", "true"}, exitUsage},
+		{"MissingFile", []string{"-f", "does-not-exist.env", "true"}, exitFail},
+		{"NotFound", []string{"-f", os.DevNull, "envparse-does-not-exist"}, exitNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			if code := run(append([]string{"exec"}, c.args...), strings.NewReader(""), &stdout, &stderr); code != c.code {
+				t.Errorf("expected exit code %d but found %d: %s", c.code, code, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import "syscall"
+
+// execProcess replaces the current process with the program at path so
+// signals and the exit code pass through directly. Only returns on error.
+func execProcess(path string, argv, env []string) (int, error) {
+	return 0, syscall.Exec(path, argv, env)
+}
+
+// defaultPath returns the search path used when the environment has no PATH,
+// matching execvp.
+func defaultPath() string {
+	return "/bin:/usr/bin"
+}
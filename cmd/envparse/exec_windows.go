@@ -0,0 +1,43 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// execProcess runs the program at path and returns its exit code. Windows
+// cannot replace the current process, so interrupts are ignored while the
+// program runs and handled by it instead, as they are sent to the whole
+// console.
+func execProcess(path string, argv, env []string) (int, error) {
+	cmd := exec.Command(path, argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	signal.Ignore(os.Interrupt)
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+	return 0, nil
+}
+
+// defaultPath returns the search path used when the environment has no PATH.
+// Windows searches the PATH of the parent process when starting a program.
+func defaultPath() string {
+	return os.Getenv("PATH")
+}
@@ -0,0 +1,130 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// runFmt formats every file, writing the result to stdout, back to the file,
+// or only checking whether the file is formatted.
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: envparse fmt [flags] [file ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Writes each file in canonical form to stdout.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	check := fs.Bool("check", false, "list files which are not formatted and exit non-zero if any")
+	write := fs.Bool("w", false, "write the result back to each file instead of stdout")
+	export := fs.String("export", "keep", "export prefixes: keep, always, or never")
+	align := fs.Bool("align", false, "align the inline comments of consecutive pairs")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	opts := envparse.FormatOptions{AlignComments: *align}
+	switch *export {
+	case "keep":
+		opts.Export = envparse.ExportKeep
+	case "always":
+		opts.Export = envparse.ExportAlways
+	case "never":
+		opts.Export = envparse.ExportNever
+	default:
+		fmt.Fprintf(stderr, "envparse: unknown export style %q\n", *export)
+		return exitUsage
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	if *write {
+		for _, name := range files {
+			if name == "-" {
+				fmt.Fprintln(stderr, "envparse: cannot use -w with stdin")
+				return exitUsage
+			}
+		}
+	}
+
+	code := exitOK
+	for _, name := range files {
+		in, out, err := formatFile(name, stdin, opts)
+		if err != nil {
+			var perr *envparse.ParseError
+			if errors.As(err, &perr) {
+				fmt.Fprintln(stderr, parseDiagnostic(name, perr))
+			} else {
+				fmt.Fprintf(stderr, "envparse: %v\n", err)
+			}
+			code = exitFail
+			continue
+		}
+
+		switch {
+		case *check:
+			if !bytes.Equal(in, out) {
+				fmt.Fprintln(stdout, name)
+				code = exitFail
+			}
+		case *write:
+			if bytes.Equal(in, out) {
+				continue
+			}
+			if err := writeFile(name, out); err != nil {
+				fmt.Fprintf(stderr, "envparse: %v\n", err)
+				code = exitFail
+			}
+		default:
+			stdout.Write(out)
+		}
+	}
+	return code
+}
+
+// formatFile returns the contents of the named file before and after
+// formatting.
+func formatFile(name string, stdin io.Reader, opts envparse.FormatOptions) ([]byte, []byte, error) {
+	f, err := openFile(name, stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	in, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := bytes.Buffer{}
+	if err := envparse.Format(bytes.NewReader(in), &out, opts); err != nil {
+		return nil, nil, err
+	}
+	return in, out.Bytes(), nil
+}
+
+// writeFile replaces the contents of the named file keeping its permissions.
+func writeFile(name string, data []byte) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, data, fi.Mode().Perm())
+}
@@ -0,0 +1,73 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmt(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		in   string
+		out  string
+		code int
+	}{
+		{"Stdout", nil, "A = 1 # x\n\n\nB='2'\n", "A=1 # x\n\nB=2\n", exitOK},
+		{"Export", []string{"-export", "never"}, "export A=1\n", "A=1\n", exitOK},
+		{"Align", []string{"-align"}, "A=1 # x\nBB=2 # y\n", "A=1  # x\nBB=2 # y\n", exitOK},
+		{"CheckFormatted", []string{"-check"}, "A=1\n", "", exitOK},
+		{"CheckUnformatted", []string{"-check"}, "A = 1\n", "-\n", exitFail},
+		{"ParseError", nil, "A\n", "", exitFail},
+		{"UnknownExport", []string{"-export", "nope"}, "", "", exitUsage},
+		{"WriteStdin", []string{"-w"}, "", "", exitUsage},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			code := run(append([]string{"fmt"}, c.args...), strings.NewReader(c.in), &stdout, &stderr)
+			if code != c.code {
+				t.Errorf("expected exit code %d but found %d: %s", c.code, code, stderr.String())
+			}
+			if stdout.String() != c.out {
+				t.Errorf("expected output:\n%s\nbut found:\n%s", c.out, stdout.String())
+			}
+		})
+	}
+}
+
+func TestFmt_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envparse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(name, []byte("A = 1\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	if code := run([]string{"fmt", "-w", name}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected exit code %d but found %d: %s", exitOK, code, stderr.String())
+	}
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(buf) != "A=1\n" {
+		t.Errorf("expected %q but found %q", "A=1\n", buf)
+	}
+	if code := run([]string{"fmt", "-check", name}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Errorf("expected exit code %d but found %d", exitOK, code)
+	}
+}
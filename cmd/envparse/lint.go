@@ -0,0 +1,202 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	envparse "github.com/hashicorp/go-envparse"
+	"github.com/hashicorp/go-envparse/lint"
+)
+
+// runLint reports the lint issues in every file, fixing them if requested.
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: envparse lint [flags] [file ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reports likely mistakes in the given files. Use -rules to list the rules.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	pf := parseFlags{}
+	pf.register(fs)
+	disable := fs.String("disable", "", "comma separated rules to disable")
+	severity := stringsFlag{}
+	fs.Var(&severity, "severity", "override the severity of a rule as rule=info|warning|error, may be repeated")
+	failOn := fs.String("fail-on", "warning", "lowest severity which fails: info, warning, or error")
+	fix := fs.Bool("fix", false, "fix issues in place, or write the fixed input to stdout for stdin")
+	jsonOut := fs.Bool("json", false, "write issues as a JSON array")
+	list := fs.Bool("rules", false, "list the rules and exit")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if *list {
+		for _, r := range lint.Rules() {
+			fix := ""
+			if r.Fixable {
+				fix = " (fixable)"
+			}
+			fmt.Fprintf(stdout, "%-20s  %-7s  %s%s\n", r.Name, r.Severity, r.Description, fix)
+		}
+		return exitOK
+	}
+
+	cfg, err := lintConfig(&pf, *disable, severity)
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitUsage
+	}
+	threshold, err := lint.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitUsage
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	// Fixed stdin is written to stdout so issues go to stderr instead
+	out := stdout
+	if *fix {
+		for _, name := range files {
+			if name == "-" {
+				out = stderr
+			}
+		}
+	}
+
+	diags := []diagnostic{}
+	failed := false
+	for _, name := range files {
+		found, err := lintFile(name, stdin, stdout, cfg, *fix)
+		if err != nil {
+			var perrs envparse.ParseErrors
+			var perr *envparse.ParseError
+			switch {
+			case errors.As(err, &perrs):
+				for _, perr := range perrs {
+					diags = append(diags, parseDiagnostic(name, perr))
+				}
+			case errors.As(err, &perr):
+				diags = append(diags, parseDiagnostic(name, perr))
+			default:
+				diags = append(diags, diagnostic{File: name, Severity: "error", Message: err.Error()})
+			}
+			failed = true
+			continue
+		}
+
+		for _, i := range found {
+			if i.Severity >= threshold {
+				failed = true
+			}
+			diags = append(diags, diagnostic{
+				File:     name,
+				Line:     i.Line,
+				Column:   i.Column,
+				Key:      i.Key,
+				Severity: i.Severity.String(),
+				Message:  i.Message,
+				Rule:     i.Rule,
+			})
+		}
+	}
+
+	if err := writeDiagnostics(out, diags, *jsonOut); err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitFail
+	}
+	if failed {
+		return exitFail
+	}
+	return exitOK
+}
+
+// lintConfig returns the lint configuration selected by the flags.
+func lintConfig(pf *parseFlags, disable string, severity []string) (lint.Config, error) {
+	cfg := lint.Config{Severity: map[string]lint.Severity{}}
+
+	opts, err := pf.options()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Options = opts
+
+	known := map[string]bool{}
+	for _, r := range lint.Rules() {
+		known[r.Name] = true
+	}
+
+	for _, name := range strings.Split(disable, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !known[name] {
+			return cfg, fmt.Errorf("unknown rule %q", name)
+		}
+		cfg.Disable = append(cfg.Disable, name)
+	}
+	for _, s := range severity {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return cfg, fmt.Errorf("invalid severity %q: expected rule=severity", s)
+		}
+		if !known[s[:eq]] {
+			return cfg, fmt.Errorf("unknown rule %q", s[:eq])
+		}
+		sev, err := lint.ParseSeverity(s[eq+1:])
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Severity[s[:eq]] = sev
+	}
+	return cfg, nil
+}
+
+// lintFile lints the named file, fixing it in place or writing the fixed
+// stdin to stdout if fix is true.
+func lintFile(name string, stdin io.Reader, stdout io.Writer, cfg lint.Config, fix bool) ([]lint.Issue, error) {
+	f, err := openFile(name, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if !fix {
+		return lint.Lint(f, cfg)
+	}
+
+	in, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	out := bytes.Buffer{}
+	issues, err := lint.Fix(bytes.NewReader(in), &out, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case name == "-":
+		_, err = stdout.Write(out.Bytes())
+	case !bytes.Equal(in, out.Bytes()):
+		err = writeFile(name, out.Bytes())
+	}
+	return issues, err
+}
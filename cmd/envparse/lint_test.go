@@ -0,0 +1,80 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		in   string
+		out  string
+		code int
+	}{
+		{"Clean", nil, "A=1\n", "", exitOK},
+		{"Warning", nil, "a=1\n", "-:1:1: warning: key a is not uppercase [lowercase-key]\n", exitFail},
+		{"Info", nil, "A=1 \n", "-:1:4: info: trailing whitespace [trailing-whitespace]\n", exitOK},
+		{"FailOnError", []string{"-fail-on", "error"}, "a=1\n", "-:1:1: warning: key a is not uppercase [lowercase-key]\n", exitOK},
+		{"Disable", []string{"-disable", "lowercase-key,key-characters"}, "a.b=1\n", "", exitOK},
+		{"Severity", []string{"-severity", "trailing-whitespace=error"}, "A=1 \n", "-:1:4: trailing whitespace [trailing-whitespace]\n", exitFail},
+		{"ParseError", nil, "A\n", "-:1:1: missing =\n", exitFail},
+		{"UnknownRule", []string{"-disable", "nope"}, "", "", exitUsage},
+		{"UnknownSeverity", []string{"-severity", "secret=fatal"}, "", "", exitUsage},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			code := run(append([]string{"lint"}, c.args...), strings.NewReader(c.in), &stdout, &stderr)
+			if code != c.code {
+				t.Errorf("expected exit code %d but found %d: %s", c.code, code, stderr.String())
+			}
+			if stdout.String() != c.out {
+				t.Errorf("expected output:\n%s\nbut found:\n%s", c.out, stdout.String())
+			}
+		})
+	}
+}
+
+func TestLint_Fix(t *testing.T) {
+	// Fixed stdin is written to stdout and issues to stderr
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := run([]string{"lint", "-fix"}, strings.NewReader("A=1 \nA=2\n"), &stdout, &stderr)
+	if code != exitOK {
+		t.Errorf("expected exit code %d but found %d: %s", exitOK, code, stderr.String())
+	}
+	if stdout.String() != "A=2\n" || stderr.String() != "" {
+		t.Errorf("unexpected output %q and %q", stdout.String(), stderr.String())
+	}
+
+	dir, err := ioutil.TempDir("", "envparse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(name, []byte("export A=1\nB=2\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stdout.Reset()
+	if code := run([]string{"lint", "-fix", name}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected exit code %d but found %d: %s", exitOK, code, stdout.String())
+	}
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := "export A=1\nexport B=2\n"; string(buf) != exp {
+		t.Errorf("expected %q but found %q", exp, buf)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Command envparse checks and works with environment variable files.
+//
+// Usage:
+//
+//	envparse <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	check       report keys which do not match the schema in .env.example
+//	exec        run a command with the variables from the given files
+//	fmt         format the given files in canonical form
+//	lint        report likely mistakes in the given files
+//	validate    report every error in the given files
+//
+// Files given as arguments default to stdin and a file named - is read from
+// stdin. Run envparse <command> -h for the flags of each command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// Exit codes returned by every command.
+const (
+	exitOK    = 0
+	exitFail  = 1
+	exitUsage = 2
+)
+
+// command is a subcommand run with its arguments.
+type command struct {
+	usage string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"check":    {"report keys which do not match the schema in .env.example", runCheck},
+	"exec":     {"run a command with the variables from the given files", runExec},
+	"fmt":      {"format the given files in canonical form", runFmt},
+	"lint":     {"report likely mistakes in the given files", runLint},
+	"validate": {"report every error in the given files", runValidate},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run the command named by the first argument and return its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		usage(stdout)
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "envparse: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+	return cmd.run(args[1:], stdin, stdout, stderr)
+}
+
+// usage writes the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: envparse <command> [flags] [file ...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s  %s\n", name, commands[name].usage)
+	}
+}
+
+// parseFlags are the flags shared by every command which parses files.
+type parseFlags struct {
+	dialect     string
+	multiline   bool
+	heredocs    bool
+	interpolate bool
+}
+
+// register the flags with fs.
+func (f *parseFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.dialect, "dialect", "default", "syntax of the files: default, docker, systemd, or dotenv")
+	fs.BoolVar(&f.multiline, "multiline", false, "allow quoted values to span lines")
+	fs.BoolVar(&f.heredocs, "heredocs", false, "allow KEY<<EOF heredoc values")
+	fs.BoolVar(&f.interpolate, "interpolate", false, "expand variable references using previous keys")
+}
+
+// options returns the parser options selected by the flags.
+func (f *parseFlags) options() ([]envparse.Option, error) {
+	opts := []envparse.Option{}
+	switch f.dialect {
+	case "default":
+	case "docker":
+		opts = append(opts, envparse.WithDialect(envparse.DialectDocker))
+	case "systemd":
+		opts = append(opts, envparse.WithDialect(envparse.DialectSystemd))
+	case "dotenv":
+		opts = append(opts, envparse.WithDialect(envparse.DialectDotenv))
+	default:
+		return nil, fmt.Errorf("unknown dialect %q", f.dialect)
+	}
+	if f.multiline {
+		opts = append(opts, envparse.WithMultiline())
+	}
+	if f.heredocs {
+		opts = append(opts, envparse.WithHeredocs())
+	}
+	if f.interpolate {
+		opts = append(opts, envparse.WithInterpolation(nil))
+	}
+	return opts, nil
+}
+
+// openFile opens the named file or returns stdin for -.
+func openFile(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(stdin), nil
+	}
+	return os.Open(name)
+}
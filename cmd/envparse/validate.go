@@ -0,0 +1,176 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// diagnostic is a problem found in a file.
+type diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Rule     string `json:"rule,omitempty"`
+}
+
+// String formats the diagnostic as file:line:col: message, omitting the line
+// and column if they are unknown. Severities other than error and the rule
+// are included if set.
+func (d diagnostic) String() string {
+	pos := d.File
+	if d.Line > 0 {
+		pos += fmt.Sprintf(":%d", d.Line)
+		if d.Column > 0 {
+			pos += fmt.Sprintf(":%d", d.Column)
+		}
+	}
+	msg := d.Message
+	if d.Rule != "" {
+		msg += " [" + d.Rule + "]"
+	}
+	if d.Severity != "error" {
+		return fmt.Sprintf("%s: %s: %s", pos, d.Severity, msg)
+	}
+	return fmt.Sprintf("%s: %s", pos, msg)
+}
+
+// runValidate parses every file and reports each error. Returns exitFail if
+// any file had an error.
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: envparse validate [flags] [file ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reports every error in the given files as file:line:col: message.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	pf := parseFlags{}
+	pf.register(fs)
+	strict := fs.Bool("strict", false, "treat duplicate keys and undefined variable references as errors")
+	jsonOut := fs.Bool("json", false, "write diagnostics as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	opts, err := pf.options()
+	if err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitUsage
+	}
+	if *strict {
+		opts = append(opts, envparse.WithDuplicates(envparse.DuplicateError), envparse.WithStrictInterpolation())
+	} else {
+		opts = append(opts, envparse.WithDuplicates(envparse.DuplicateWarn))
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	diags := []diagnostic{}
+	failed := false
+	for _, name := range files {
+		found := validateFile(name, stdin, opts)
+		for _, d := range found {
+			if d.Severity == "error" {
+				failed = true
+			}
+		}
+		diags = append(diags, found...)
+	}
+
+	if err := writeDiagnostics(stdout, diags, *jsonOut); err != nil {
+		fmt.Fprintf(stderr, "envparse: %v\n", err)
+		return exitFail
+	}
+
+	if failed {
+		return exitFail
+	}
+	return exitOK
+}
+
+// writeDiagnostics writes each diagnostic on its own line or as a JSON array.
+func writeDiagnostics(w io.Writer, diags []diagnostic, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diags)
+	}
+	for _, d := range diags {
+		if _, err := fmt.Fprintln(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFile parses the named file and returns a diagnostic for every error
+// and warning.
+func validateFile(name string, stdin io.Reader, opts []envparse.Option) []diagnostic {
+	diags := []diagnostic{}
+	f, err := openFile(name, stdin)
+	if err != nil {
+		return append(diags, diagnostic{File: name, Severity: "error", Message: err.Error()})
+	}
+	defer f.Close()
+
+	opts = append(opts[:len(opts):len(opts)],
+		envparse.WithSource(name),
+		envparse.WithCollectErrors(),
+		envparse.WithOnWarning(func(err error) {
+			d := diagnostic{File: name, Severity: "warning", Message: err.Error()}
+			var derr *envparse.DuplicateKeyError
+			if errors.As(err, &derr) {
+				d.Line, d.Key = derr.Line, derr.Key
+			}
+			diags = append(diags, d)
+		}),
+	)
+
+	_, err = envparse.ParsePairsWithOptions(f, opts...)
+	var perrs envparse.ParseErrors
+	var perr *envparse.ParseError
+	switch {
+	case err == nil:
+	case errors.As(err, &perrs):
+		for _, perr := range perrs {
+			diags = append(diags, parseDiagnostic(name, perr))
+		}
+	case errors.As(err, &perr):
+		diags = append(diags, parseDiagnostic(name, perr))
+	default:
+		diags = append(diags, diagnostic{File: name, Severity: "error", Message: err.Error()})
+	}
+	return diags
+}
+
+// parseDiagnostic returns the diagnostic for a ParseError in the named file.
+func parseDiagnostic(name string, perr *envparse.ParseError) diagnostic {
+	return diagnostic{
+		File:     name,
+		Line:     perr.Line,
+		Column:   perr.Column,
+		Key:      perr.Key,
+		Severity: "error",
+		Message:  perr.Err.Error(),
+	}
+}
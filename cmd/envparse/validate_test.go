@@ -0,0 +1,101 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		in   string
+		out  string
+		code int
+	}{
+		{"Valid", nil, "A=1\nB='2'\n", "", exitOK},
+		{"Errors", []string{"-"}, "A=1\nB=\"x\nC\n", "-:2:3: unmatched \"\n-:3:1: missing =\n", exitFail},
+		{"Duplicate", nil, "A=1\nA=2\n", "-:2: warning: duplicate key A (first defined on line 1)\n", exitOK},
+		{"StrictDuplicate", []string{"-strict"}, "A=1\nA=2\n", "-:2:1: duplicate key A (first defined on line 1)\n", exitFail},
+		{"StrictInterpolation", []string{"-strict", "-interpolate"}, "A=$B\n", "-:1:3: undefined variable: B\n", exitFail},
+		{"Dialect", []string{"-dialect", "dotenv"}, "A: `x`\n", "", exitOK},
+		{"Multiline", []string{"-multiline"}, "A='x\ny'\n", "", exitOK},
+		{"MissingFile", []string{"does-not-exist.env"}, "", "does-not-exist.env: open does-not-exist.env: no such file or directory\n", exitFail},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			args := append([]string{"validate"}, c.args...)
+			code := run(args, strings.NewReader(c.in), &stdout, &stderr)
+			if code != c.code {
+				t.Errorf("expected exit code %d but found %d: %s", c.code, code, stderr.String())
+			}
+			if stdout.String() != c.out {
+				t.Errorf("expected output:\n%s\nbut found:\n%s", c.out, stdout.String())
+			}
+		})
+	}
+}
+
+func TestValidate_JSON(t *testing.T) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := run([]string{"validate", "-json"}, strings.NewReader("A=1\nA=2\nB=\"x\n"), &stdout, &stderr)
+	if code != exitFail {
+		t.Errorf("expected exit code %d but found %d: %s", exitFail, code, stderr.String())
+	}
+
+	diags := []diagnostic{}
+	if err := json.Unmarshal(stdout.Bytes(), &diags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []diagnostic{
+		{File: "-", Line: 2, Key: "A", Severity: "warning", Message: "duplicate key A (first defined on line 1)"},
+		{File: "-", Line: 3, Column: 3, Key: "B", Severity: "error", Message: `unmatched "`},
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics but found %d: %#v", len(expected), len(diags), diags)
+	}
+	for i := range expected {
+		if diags[i] != expected[i] {
+			t.Errorf("expected %#v but found %#v", expected[i], diags[i])
+		}
+	}
+
+	// No diagnostics is an empty array rather than null
+	stdout.Reset()
+	if code := run([]string{"validate", "-json"}, strings.NewReader("A=1\n"), &stdout, &stderr); code != exitOK {
+		t.Errorf("expected exit code %d but found %d", exitOK, code)
+	}
+	if s := strings.TrimSpace(stdout.String()); s != "[]" {
+		t.Errorf("expected [] but found %s", s)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"NoCommand", nil, exitUsage},
+		{"UnknownCommand", []string{"nope"}, exitUsage},
+		{"Help", []string{"help"}, exitOK},
+		{"UnknownDialect", []string{"validate", "-dialect", "nope"}, exitUsage},
+		{"UnknownFlag", []string{"validate", "-nope"}, exitUsage},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+			if code := run(c.args, strings.NewReader(""), &stdout, &stderr); code != c.code {
+				t.Errorf("expected exit code %d but found %d", c.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingRequired = fmt.Errorf("missing required key")
+	ErrUnsupportedType = fmt.Errorf("unsupported field type")
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// DecodeError is returned when a value cannot be decoded into a struct field
+// or a required key is missing. If the value was read from the input the
+// DecodeError is wrapped in a ParseError with the line the key was defined on.
+type DecodeError struct {
+	Key   string
+	Field string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s (field %s): %v", e.Key, e.Field, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads environment variables from an input into structs.
+//
+// Struct fields are decoded from the key named in their env tag:
+//
+//	type Config struct {
+//		Host    string        `env:"HOST,required"`
+//		Port    int           `env:"PORT,default=8080"`
+//		Timeout time.Duration `env:"TIMEOUT"`
+//		Tags    []string      `env:"TAGS" envSeparator:";"`
+//		DB      DBConfig      `envPrefix:"DB_"`
+//	}
+//
+// Supported field types are strings, bools, integers, floats, time.Duration,
+// types implementing encoding.TextUnmarshaler, and pointers or slices of
+// those. Slice values are split on envSeparator which defaults to ",".
+//
+// Nested struct fields without an env tag are decoded recursively with their
+// envPrefix prepended to the keys of their fields. Fields without an env tag
+// and fields tagged env:"-" are ignored.
+//
+// A default must be the last tag option as it may contain commas. Missing keys
+// without a default are left unchanged unless marked required.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Unmarshal environment variables from an io.Reader into the struct pointed
+// to by v. See Decoder for details.
+func Unmarshal(r io.Reader, v interface{}) error {
+	return NewDecoder(r).Decode(v)
+}
+
+// decodeEntry is a value and the line it was read from.
+type decodeEntry struct {
+	val  string
+	line int
+}
+
+// Decode environment variables from the input into the struct pointed to by
+// v. Returns a ParseError if the input is invalid or a value cannot be
+// decoded, and a DecodeError if a required key is missing.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a non-nil struct pointer but found %T", v)
+	}
+
+	env := make(map[string]decodeEntry)
+	parser := New(d.r)
+
+	for {
+		kv, err := parser.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		env[kv.Key] = decodeEntry{val: kv.Val, line: parser.i}
+	}
+
+	return decodeStruct(rv.Elem(), "", "", env)
+}
+
+// decodeStruct decodes env into the fields of rv. Keys are prefixed with
+// prefix and field names with path.
+func decodeStruct(rv reflect.Value, prefix, path string, env map[string]decodeEntry) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			// Unexported
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("env")
+		if tag == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		field := path + sf.Name
+
+		if !ok {
+			if sf.Type.Kind() == reflect.Struct && !isTextUnmarshaler(fv) {
+				err := decodeStruct(fv, prefix+sf.Tag.Get("envPrefix"), field+".", env)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		name, required, def, hasDef := parseTag(tag)
+		key := prefix + name
+
+		e, found := env[key]
+		val := e.val
+		if !found {
+			switch {
+			case hasDef:
+				val = def
+			case required:
+				return &DecodeError{Key: key, Field: field, Err: ErrMissingRequired}
+			default:
+				continue
+			}
+		}
+
+		sep, ok := sf.Tag.Lookup("envSeparator")
+		if !ok {
+			sep = ","
+		}
+
+		if err := setField(fv, val, sep); err != nil {
+			derr := &DecodeError{Key: key, Field: field, Err: err}
+			if found {
+				return parseError(e.line, derr)
+			}
+			return derr
+		}
+	}
+	return nil
+}
+
+// parseTag splits an env tag into its key name and options.
+func parseTag(tag string) (name string, required bool, def string, hasDef bool) {
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for i, opt := range parts[1:] {
+		switch {
+		case opt == "required":
+			required = true
+		case strings.HasPrefix(opt, "default="):
+			// Defaults consume the remainder of the tag
+			def = strings.TrimPrefix(strings.Join(parts[i+1:], ","), "default=")
+			hasDef = true
+			return
+		}
+	}
+	return
+}
+
+// isTextUnmarshaler returns true if a pointer to fv implements
+// encoding.TextUnmarshaler.
+func isTextUnmarshaler(fv reflect.Value) bool {
+	return fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType)
+}
+
+// setField converts s to the type of fv and sets it.
+func setField(fv reflect.Value, s string, sep string) error {
+	if fv.Kind() == reflect.Ptr {
+		nv := reflect.New(fv.Type().Elem())
+		if err := setField(nv.Elem(), s, sep); err != nil {
+			return err
+		}
+		fv.Set(nv)
+		return nil
+	}
+
+	if isTextUnmarshaler(fv) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			fv.SetBytes([]byte(s))
+			return nil
+		}
+
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, sep)
+		}
+		sv := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setField(sv.Index(i), part, sep); err != nil {
+				return err
+			}
+		}
+		fv.Set(sv)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDBConfig struct {
+	Host string `env:"HOST,default=localhost"`
+	Port uint16 `env:"PORT,required"`
+}
+
+type testConfig struct {
+	Name     string        `env:"NAME,required"`
+	Port     int           `env:"PORT,default=8080"`
+	Debug    bool          `env:"DEBUG"`
+	Ratio    float64       `env:"RATIO"`
+	Timeout  time.Duration `env:"TIMEOUT,default=1m"`
+	Tags     []string      `env:"TAGS"`
+	Ports    []int         `env:"PORTS" envSeparator:";"`
+	IP       net.IP        `env:"IP"`
+	Optional *int          `env:"OPTIONAL"`
+	Missing  *int          `env:"MISSING"`
+	Default  string        `env:"DEFAULT,default=a,b"`
+	DB       testDBConfig  `envPrefix:"DB_"`
+	Ignored  string        `env:"-"`
+	Untagged string
+	private  string
+}
+
+func TestUnmarshal(t *testing.T) {
+	buf := `
+NAME=svc
+DEBUG=true
+RATIO=0.5
+TAGS=a,b,c
+PORTS=80;443
+IP=10.0.0.1
+OPTIONAL=7
+DB_PORT=5432
+Ignored=x
+Untagged=x
+`
+	cfg := testConfig{Untagged: "keep"}
+	if err := Unmarshal(strings.NewReader(buf), &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seven := 7
+	exp := testConfig{
+		Name:     "svc",
+		Port:     8080,
+		Debug:    true,
+		Ratio:    0.5,
+		Timeout:  time.Minute,
+		Tags:     []string{"a", "b", "c"},
+		Ports:    []int{80, 443},
+		IP:       net.ParseIP("10.0.0.1"),
+		Optional: &seven,
+		Default:  "a,b",
+		DB:       testDBConfig{Host: "localhost", Port: 5432},
+		Untagged: "keep",
+	}
+	if !reflect.DeepEqual(cfg, exp) {
+		t.Errorf("expected %#v\nbut found %#v", exp, cfg)
+	}
+}
+
+func TestUnmarshal_Err(t *testing.T) {
+	cases := []struct {
+		name  string
+		buf   string
+		key   string
+		field string
+		line  int
+		err   error
+	}{
+		{"MissingRequired", "DB_PORT=1\n", "NAME", "Name", 0, ErrMissingRequired},
+		{"MissingNestedRequired", "NAME=x\n", "DB_PORT", "DB.Port", 0, ErrMissingRequired},
+		{"InvalidInt", "NAME=x\nDB_PORT=1\n\nPORT=eighty\n", "PORT", "Port", 4, nil},
+		{"Overflow", "NAME=x\nDB_PORT=65536\n", "DB_PORT", "DB.Port", 2, nil},
+		{"InvalidSliceElem", "NAME=x\nDB_PORT=1\nPORTS=1;x\n", "PORTS", "Ports", 3, nil},
+		{"InvalidTextUnmarshaler", "NAME=x\nDB_PORT=1\nIP=nope\n", "IP", "IP", 3, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Unmarshal(strings.NewReader(c.buf), &testConfig{})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("expected a *DecodeError but found %T: %v", err, err)
+			}
+			if derr.Key != c.key || derr.Field != c.field {
+				t.Errorf("expected key %q field %q but found %q %q", c.key, c.field, derr.Key, derr.Field)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v but found %v", c.err, err)
+			}
+
+			var perr *ParseError
+			if ok := errors.As(err, &perr); ok != (c.line > 0) {
+				t.Fatalf("expected ParseError=%t but found %v", c.line > 0, err)
+			}
+			if perr != nil && perr.Line != c.line {
+				t.Errorf("expected error on line %d but found %d", c.line, perr.Line)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Target(t *testing.T) {
+	var cfg testConfig
+	targets := []interface{}{nil, cfg, (*testConfig)(nil), new(int)}
+	for _, target := range targets {
+		if err := Unmarshal(strings.NewReader(""), target); err == nil {
+			t.Errorf("expected an error for %T", target)
+		}
+	}
+
+	var bad struct {
+		M map[string]string `env:"M"`
+	}
+	err := Unmarshal(strings.NewReader("M=x"), &bad)
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected %v but found %v", ErrUnsupportedType, err)
+	}
+}
@@ -0,0 +1,636 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidUTF8       = fmt.Errorf("invalid UTF-8")
+	ErrUnmatchedBacktick = fmt.Errorf("unmatched `")
+)
+
+// errBareCR is returned for a carriage return which would end a line for
+// systemd but not for the Parser.
+var errBareCR = fmt.Errorf("carriage return before end of line")
+
+// Dialect selects the syntax used to parse each line.
+type Dialect uint8
+
+const (
+	// DialectDefault is the syntax described in the package documentation.
+	DialectDefault Dialect = iota
+
+	// DialectDocker matches the --env-file flag of docker run:
+	//
+	//   - Leading whitespace is trimmed and lines starting with # are
+	//     comments.
+	//   - Everything after the first = is the value exactly as written.
+	//     Quotes and escapes are not interpreted, inline comments are not
+	//     removed, and trailing whitespace is kept.
+	//   - Keys may contain any character except whitespace and =, so export
+	//     prefixes are invalid.
+	//   - A bare KEY without an = inherits its value from the lookup set with
+	//     WithInherit, and is skipped if it is not found.
+	//   - Empty values are returned as with WithEmptyValues.
+	//   - Lines must be valid UTF-8, and a leading byte order mark is
+	//     removed.
+	//
+	// Interpolation, multiline values, and heredocs are not supported.
+	DialectDocker
+
+	// DialectSystemd matches the EnvironmentFile= setting of systemd units:
+	//
+	//   - Leading whitespace is trimmed and lines starting with # or ; are
+	//     comments. Lines without an = are ignored.
+	//   - Keys must be of the form [A-Za-z_][A-Za-z0-9_]* and export prefixes
+	//     are invalid.
+	//   - Unquoted values keep interior whitespace and quotes after the first
+	//     character. A backslash keeps the following character and a
+	//     backslash at the end of a line continues the value on the next
+	//     line. Trailing whitespace is trimmed and # does not start a comment.
+	//   - Single quoted values may span lines and have no escape sequences.
+	//   - Double quoted values may span lines. A backslash before any of
+	//     "\`$ keeps only that character, a backslash before a newline
+	//     continues the line, and any other backslash is kept as-is.
+	//   - Quoted and unquoted parts may be combined, and whitespace between
+	//     them is removed.
+	//   - Empty values are returned as with WithEmptyValues.
+	//   - Lines must be valid UTF-8 and may not contain NUL, byte order
+	//     marks, or Unicode noncharacters.
+	//
+	// Files which systemd would accept but which are likely mistakes, such as
+	// unterminated quotes, keys it would ignore, or carriage returns outside
+	// of quotes that are not part of a line ending, are errors.
+	//
+	// Interpolation, multiline options, and heredocs are not supported.
+	DialectSystemd
+
+	// DialectDotenv matches the dotenv libraries for Node, Ruby, and Go:
+	//
+	//   - Leading whitespace is trimmed and lines starting with # are
+	//     comments.
+	//   - Keys must be of the form [A-Za-z0-9_.-]+ and may have an export
+	//     prefix. Either = or : separates the key from the value.
+	//   - Unquoted values have surrounding whitespace trimmed. A # only
+	//     starts a comment at the start of the value or after whitespace, so
+	//     FOO=a#b is "a#b".
+	//   - Values may be single quoted, double quoted, or quoted with
+	//     backticks, and quoted values may span lines. Only whitespace and a
+	//     comment may follow the closing quote.
+	//   - Single and backtick quoted values have no escape sequences.
+	//   - Double quoted values replace \n, \r, and \t with a newline,
+	//     carriage return, and tab, and a backslash before any of "\$ keeps
+	//     only that character. Any other backslash is kept as-is.
+	//   - Control characters are allowed and empty values are returned as
+	//     with WithEmptyValues.
+	//
+	// Lines without a separator are errors. If WithInterpolation is used
+	// references are expanded in unquoted and double quoted values.
+	//
+	// WithMultiline and heredocs are not supported as quoted values always
+	// span lines.
+	DialectDotenv
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// parseDockerLine parses the current line using DialectDocker.
+func (p *Parser) parseDockerLine(ln []byte, info *lineInfo) ([]byte, []byte, error) {
+	if info != nil {
+		*info = lineInfo{raw: append([]byte{}, ln...)}
+	}
+
+	// Offsets of subslices are relative to the original line
+	text := ln
+	at := func(b []byte) int {
+		return cap(text) - cap(b)
+	}
+	fail := func(off int, err error) ([]byte, []byte, error) {
+		return nil, nil, lineParseError(p.i, p.off, text, errAt(off, nil, err))
+	}
+
+	if p.i == 1 {
+		ln = bytes.TrimPrefix(ln, utf8BOM)
+	}
+	if i := invalidUTF8(ln); i >= 0 {
+		return fail(at(ln)+i, ErrInvalidUTF8)
+	}
+
+	ln = bytes.TrimLeftFunc(ln, unicode.IsSpace)
+	if len(ln) == 0 || ln[0] == '#' {
+		return empty, empty, nil
+	}
+
+	key, val, hasVal := ln, empty, false
+	if sep := bytes.IndexByte(ln, '='); sep >= 0 {
+		key, val, hasVal = ln[:sep], ln[sep+1:], true
+	}
+	if len(key) == 0 {
+		return fail(at(key), ErrEmptyKey)
+	}
+	if i := bytes.IndexAny(key, " \t"); i >= 0 {
+		return fail(at(key)+i, fmt.Errorf("key %q contains whitespace", key))
+	}
+	if p.opts.checkKey != nil {
+		if err := p.opts.checkKey(string(key)); err != nil {
+			return fail(at(key), err)
+		}
+	}
+
+	if !hasVal {
+		if p.opts.inherit == nil {
+			return empty, empty, nil
+		}
+		v, ok := p.opts.inherit(string(key))
+		if !ok {
+			return empty, empty, nil
+		}
+		val = []byte(v)
+	}
+
+	if info != nil && len(val) > 0 {
+		info.quotes = Unquoted
+	}
+	return key, val, nil
+}
+
+// invalidUTF8 returns the index of the first invalid UTF-8 sequence in b or
+// -1 if b is valid.
+func invalidUTF8(b []byte) int {
+	for i := 0; i < len(b); {
+		r, n := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && n == 1 {
+			return i
+		}
+		i += n
+	}
+	return -1
+}
+
+// parseSystemdLine parses the current line using DialectSystemd, reading
+// subsequent lines for quoted values and line continuations.
+func (p *Parser) parseSystemdLine(ln []byte, info *lineInfo) ([]byte, []byte, error) {
+	segs := []segment{{start: 0, line: p.i, off: p.off}}
+	buf := ln
+	fail := func(off int, key []byte, err error) ([]byte, []byte, error) {
+		return nil, nil, segmentError(buf, segs, errAt(off, key, err))
+	}
+
+	if i, err := checkSystemdText(buf); err != nil {
+		return fail(i, nil, err)
+	}
+
+	start := len(buf) - len(bytes.TrimLeft(buf, " \t\r"))
+	if start == len(buf) {
+		return empty, empty, nil
+	}
+	if buf[start] == '#' || buf[start] == ';' {
+		if i := crBreak(buf[start:]); i >= 0 {
+			return fail(start+i, nil, errBareCR)
+		}
+		return empty, empty, nil
+	}
+
+	sep := bytes.IndexByte(buf[start:], '=')
+	if sep < 0 {
+		if i := crBreak(buf[start:]); i >= 0 {
+			return fail(start+i, nil, errBareCR)
+		}
+		// Like systemd, lines without a separator are ignored
+		return empty, empty, nil
+	}
+	sep += start
+
+	keyEnd := start + len(bytes.TrimRight(buf[start:sep], " \t"))
+	key := buf[start:keyEnd]
+	if i := bytes.IndexByte(key, '\r'); i >= 0 {
+		return fail(start+i, nil, errBareCR)
+	}
+	if len(key) == 0 {
+		return fail(sep, nil, ErrEmptyKey)
+	}
+	if p.opts.checkKey != nil {
+		if err := p.opts.checkKey(string(key)); err != nil {
+			return fail(start, nil, err)
+		}
+	} else if i, err := checkShellKeyAt(key); err != nil {
+		return fail(start+i, nil, err)
+	}
+
+	const (
+		preValue = iota
+		value
+		valueEscape
+		singleValue
+		doubleValue
+		doubleEscape
+	)
+
+	val := []byte{}
+	var quotes Quote
+	state := preValue
+	quoteStart := 0
+
+	// lastWS is the start of trailing whitespace in an unquoted value or -1
+	lastWS := -1
+
+	done := false
+	for i := sep + 1; !done; i++ {
+		if i == len(buf) {
+			// Newlines end unquoted values, continue escaped lines, and are
+			// kept within quotes
+			switch state {
+			case preValue, value:
+				done = true
+				continue
+			case valueEscape:
+				state = value
+			case doubleEscape:
+				state = doubleValue
+			case singleValue, doubleValue:
+				val = append(val, '\n')
+			}
+
+			if !p.scan() {
+				if err := p.scanErr(); err != nil {
+					return nil, nil, err
+				}
+				switch state {
+				case singleValue:
+					return fail(quoteStart, buf[start:keyEnd], ErrUnmatchedSingle)
+				case doubleValue:
+					return fail(quoteStart, buf[start:keyEnd], ErrUnmatchedDouble)
+				}
+				done = true
+				continue
+			}
+
+			// The scanner reuses its buffer so the first line must be copied
+			if len(segs) == 1 {
+				buf = append([]byte{}, buf...)
+			}
+			buf = append(buf, '\n')
+			segs = append(segs, segment{start: len(buf), line: p.i, off: p.off})
+			buf = append(buf, p.s.Bytes()...)
+			if j, err := checkSystemdText(p.s.Bytes()); err != nil {
+				return fail(len(buf)-len(p.s.Bytes())+j, buf[start:keyEnd], err)
+			}
+
+			// Skip the newline joining the lines
+			continue
+		}
+
+		c := buf[i]
+		switch state {
+		case preValue, value:
+			if c == '\r' {
+				// Carriage returns end unquoted values
+				if j := crBreak(buf[i:]); j >= 0 {
+					return fail(i+j, buf[start:keyEnd], errBareCR)
+				}
+				done = true
+				continue
+			}
+		}
+
+		switch state {
+		case preValue:
+			switch c {
+			case '\'':
+				state, quoteStart = singleValue, i
+				quotes |= SingleQuoted
+			case '"':
+				state, quoteStart = doubleValue, i
+				quotes |= DoubleQuoted
+			case '\\':
+				state = valueEscape
+			case ' ', '\t':
+			default:
+				state = value
+				quotes |= Unquoted
+				val = append(val, c)
+			}
+		case value:
+			switch c {
+			case '\\':
+				state = valueEscape
+				lastWS = -1
+			case ' ', '\t':
+				if lastWS < 0 {
+					lastWS = len(val)
+				}
+				val = append(val, c)
+			default:
+				lastWS = -1
+				val = append(val, c)
+			}
+		case valueEscape:
+			state = value
+			if c != '\r' {
+				quotes |= Unquoted
+				val = append(val, c)
+			}
+		case singleValue:
+			if c == '\'' {
+				state = preValue
+			} else {
+				val = append(val, c)
+			}
+		case doubleValue:
+			switch c {
+			case '"':
+				state = preValue
+			case '\\':
+				state = doubleEscape
+			default:
+				val = append(val, c)
+			}
+		case doubleEscape:
+			state = doubleValue
+			switch c {
+			case '"', '\\', '`', '$':
+			default:
+				val = append(val, '\\')
+			}
+			val = append(val, c)
+		}
+	}
+
+	if state == value && lastWS >= 0 {
+		val = val[:lastWS]
+	}
+	if info != nil {
+		*info = lineInfo{
+			raw:    append([]byte{}, bytes.TrimSuffix(buf, []byte{'\r'})...),
+			quotes: quotes,
+		}
+	}
+	return buf[start:keyEnd], val, nil
+}
+
+// parseDotenvLine parses the current line using DialectDotenv, reading
+// subsequent lines for quoted values.
+func (p *Parser) parseDotenvLine(ln []byte, info *lineInfo) ([]byte, []byte, error) {
+	segs := []segment{{start: 0, line: p.i, off: p.off}}
+	buf := ln
+	fail := func(off int, key []byte, err error) ([]byte, []byte, error) {
+		return nil, nil, segmentError(buf, segs, errAt(off, key, err))
+	}
+
+	start := len(buf) - len(bytes.TrimLeft(buf, " \t"))
+	if start == len(buf) || buf[start] == '#' {
+		return empty, empty, nil
+	}
+
+	sep := bytes.IndexAny(buf[start:], "=:")
+	if sep < 0 {
+		return fail(start, nil, ErrMissingSeparator)
+	}
+	sep += start
+
+	keyEnd := start + len(bytes.TrimRight(buf[start:sep], " \t"))
+	key := buf[start:keyEnd]
+	export := false
+	if rest := bytes.TrimPrefix(key, []byte("export")); len(rest) < len(key) && !p.opts.noExport {
+		// Only trim leading export if there's another key name
+		if k := bytes.TrimLeft(rest, " \t"); len(k) > 0 && len(k) < len(rest) {
+			key, export = k, true
+		}
+	}
+	keyStart := keyEnd - len(key)
+	if len(key) == 0 {
+		return fail(sep, nil, ErrEmptyKey)
+	}
+	if p.opts.checkKey != nil {
+		if err := p.opts.checkKey(string(key)); err != nil {
+			return fail(keyStart, nil, err)
+		}
+	} else if i, err := checkDotenvKeyAt(key); err != nil {
+		return fail(keyStart+i, nil, err)
+	}
+
+	val := []byte{}
+	var quotes Quote
+	var comment []byte
+
+	i := sep + 1
+	for i < len(buf) && (buf[i] == ' ' || buf[i] == '\t') {
+		i++
+	}
+
+	switch {
+	case i == len(buf):
+	case buf[i] == '#':
+		comment = bytes.TrimSpace(buf[i+1:])
+	case buf[i] == '\'' || buf[i] == '"' || buf[i] == '`':
+		q, quoteStart := buf[i], i
+		quotes = dotenvQuote(q)
+		for i++; ; i++ {
+			if i == len(buf) {
+				// Quoted values continue until their closing quote
+				if !p.scan() {
+					if err := p.scanErr(); err != nil {
+						return nil, nil, err
+					}
+					return fail(quoteStart, buf[keyStart:keyEnd], dotenvUnmatched(q))
+				}
+
+				// The scanner reuses its buffer so the first line must be
+				// copied
+				if len(segs) == 1 {
+					buf = append([]byte{}, buf...)
+				}
+				buf = append(buf, '\n')
+				segs = append(segs, segment{start: len(buf), line: p.i, off: p.off})
+				buf = append(buf, p.s.Bytes()...)
+				val = append(val, '\n')
+
+				// Skip the newline joining the lines
+				continue
+			}
+
+			c := buf[i]
+			if c == q {
+				break
+			}
+			if q != '"' {
+				val = append(val, c)
+				continue
+			}
+
+			switch {
+			case c == '\\' && i+1 < len(buf):
+				i++
+				switch e := buf[i]; e {
+				case 'n':
+					val = append(val, '\n')
+				case 'r':
+					val = append(val, '\r')
+				case 't':
+					val = append(val, '\t')
+				case '"', '\\', '$':
+					val = append(val, e)
+				default:
+					val = append(val, c, e)
+				}
+			case c == '$' && p.ex != nil:
+				exp, n, err := p.ex.expand(buf[i:])
+				if err != nil {
+					return fail(i, buf[keyStart:keyEnd], err)
+				}
+				val = append(val, exp...)
+				i += n - 1
+			default:
+				val = append(val, c)
+			}
+		}
+
+		rest := bytes.TrimLeft(buf[i+1:], " \t")
+		if len(rest) > 0 && rest[0] != '#' {
+			return fail(len(buf)-len(rest), buf[keyStart:keyEnd], fmt.Errorf("unexpected %q after quoted value", rest[0]))
+		}
+		if len(rest) > 0 {
+			comment = bytes.TrimSpace(rest[1:])
+		}
+	default:
+		quotes = Unquoted
+
+		// Track last non-WS char for trimming on trailing comments
+		lastSig := 0
+	unquoted:
+		for ; i < len(buf); i++ {
+			c := buf[i]
+			switch {
+			case c == '#' && (buf[i-1] == ' ' || buf[i-1] == '\t'):
+				// Start of a comment, nothing left to parse
+				comment = bytes.TrimSpace(buf[i+1:])
+				break unquoted
+			case c == '$' && p.ex != nil:
+				exp, n, err := p.ex.expand(buf[i:])
+				if err != nil {
+					return fail(i, key, err)
+				}
+				val = append(val, exp...)
+				i += n - 1
+				if len(exp) > 0 {
+					lastSig = len(val)
+				}
+			case c == ' ' || c == '\t':
+				val = append(val, c)
+			default:
+				val = append(val, c)
+				lastSig = len(val)
+			}
+		}
+		val = val[:lastSig]
+	}
+
+	if info != nil {
+		*info = lineInfo{
+			raw:     append([]byte{}, buf...),
+			quotes:  quotes,
+			export:  export,
+			comment: comment,
+		}
+	}
+	return buf[keyStart:keyEnd], val, nil
+}
+
+// dotenvQuote returns the Quote style of the quote character q.
+func dotenvQuote(q byte) Quote {
+	switch q {
+	case '\'':
+		return SingleQuoted
+	case '"':
+		return DoubleQuoted
+	default:
+		return BacktickQuoted
+	}
+}
+
+// dotenvUnmatched returns the error for a value missing its closing quote q.
+func dotenvUnmatched(q byte) error {
+	switch q {
+	case '\'':
+		return ErrUnmatchedSingle
+	case '"':
+		return ErrUnmatchedDouble
+	default:
+		return ErrUnmatchedBacktick
+	}
+}
+
+// checkDotenvKeyAt returns an error and the index of the offending character
+// if key is not a valid dotenv key name.
+func checkDotenvKeyAt(key []byte) (int, error) {
+	for i, v := range key {
+		switch {
+		case v == '_', v == '.', v == '-':
+		case v >= 'A' && v <= 'Z':
+		case v >= 'a' && v <= 'z':
+		case v >= '0' && v <= '9':
+		default:
+			return i, fmt.Errorf("key characters must be [A-Za-z0-9_.-] but found %q", v)
+		}
+	}
+	return 0, nil
+}
+
+// scanRawLines is bufio.ScanLines without removing carriage returns.
+func scanRawLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// crBreak returns the index of the first carriage return in b which is
+// followed by something other than whitespace or -1 if there is none.
+func crBreak(b []byte) int {
+	for i, c := range b {
+		if c == '\r' && len(bytes.TrimLeft(b[i:], " \t\r")) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkShellKeyAt returns an error and the index of the offending character
+// if key is not a valid POSIX shell variable name.
+func checkShellKeyAt(key []byte) (int, error) {
+	if i, err := checkKeyAt(key); err != nil {
+		return i, err
+	}
+	if i := bytes.IndexAny(key, "./"); i >= 0 {
+		return i, fmt.Errorf("key characters must be [A-Za-z0-9_] but found %q", key[i])
+	}
+	return 0, nil
+}
+
+// checkSystemdText returns an error and its index if b contains invalid
+// UTF-8 or a character systemd does not allow in environment files.
+func checkSystemdText(b []byte) (int, error) {
+	for i := 0; i < len(b); {
+		r, n := utf8.DecodeRune(b[i:])
+		switch {
+		case r == utf8.RuneError && n == 1:
+			return i, ErrInvalidUTF8
+		case r == 0, r == '\ufeff', r >= 0xfdd0 && r <= 0xfdef, r&0xfffe == 0xfffe:
+			return i, fmt.Errorf("invalid character %U", r)
+		}
+		i += n
+	}
+	return -1, nil
+}
@@ -0,0 +1,381 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_Docker(t *testing.T) {
+	buf := "\xef\xbb\xbfA=1\n" +
+		"  # comment\n" +
+		"\tQUOTED=\"foo\" 'bar'\n" +
+		"COMMENT=foo # bar\n" +
+		"SPACES= foo  \r\n" +
+		"EMPTY=\n" +
+		"EQUALS=a=b\n" +
+		"lower.dotted-key=1\n" +
+		"HOME\n" +
+		"MISSING\n" +
+		"ESCAPE=\\n\\t\n" +
+		"\n"
+
+	lookup := func(k string) (string, bool) {
+		if k == "HOME" {
+			return "/root", true
+		}
+		return "", false
+	}
+
+	env, err := ParseWithOptions(strings.NewReader(buf), WithDialect(DialectDocker), WithInherit(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"A":                "1",
+		"QUOTED":           `"foo" 'bar'`,
+		"COMMENT":          "foo # bar",
+		"SPACES":           " foo  ",
+		"EMPTY":            "",
+		"EQUALS":           "a=b",
+		"lower.dotted-key": "1",
+		"HOME":             "/root",
+		"ESCAPE":           `\n\t`,
+	}
+	if len(env) != len(expected) {
+		t.Errorf("expected %d keys but found %d: %#v", len(expected), len(env), env)
+	}
+	for k, v := range expected {
+		if found, ok := env[k]; !ok || found != v {
+			t.Errorf("expected %s=%q but found %q", k, v, found)
+		}
+	}
+
+	// Without a lookup bare keys are skipped
+	env, err = ParseWithOptions(strings.NewReader("HOME\nA=1\n"), WithDialect(DialectDocker))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := env["HOME"]; ok || env["A"] != "1" {
+		t.Errorf("expected only A but found %#v", env)
+	}
+}
+
+func TestParse_Docker_Err(t *testing.T) {
+	cases := []struct {
+		name string
+		buf  string
+		line int
+		col  int
+		err  error
+	}{
+		{"Export", "A=1\nexport B=2\n", 2, 7, nil},
+		{"KeySpace", "A =1\n", 1, 2, nil},
+		{"BareKeySpace", "  A B\n", 1, 4, nil},
+		{"EmptyKey", "=1\n", 1, 1, ErrEmptyKey},
+		{"InvalidUTF8", "A=1\nB=a\xffb\n", 2, 4, ErrInvalidUTF8},
+		{"InvalidUTF8Comment", "# \xff\n", 1, 3, ErrInvalidUTF8},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), WithDialect(DialectDocker))
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T", err)
+			}
+			if perr.Line != c.line || perr.Column != c.col {
+				t.Errorf("expected error at %d:%d but found %d:%d: %v", c.line, c.col, perr.Line, perr.Column, err)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v but found %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParse_Docker_KeyCheck(t *testing.T) {
+	_, err := ParseWithOptions(strings.NewReader("a.b=1\n"), WithDialect(DialectDocker), WithKeyCheck(func(k string) error {
+		return checkKey([]byte(strings.ReplaceAll(k, ".", "_")))
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = ParseWithOptions(strings.NewReader("1=1\n"), WithDialect(DialectDocker), WithKeyCheck(func(k string) error {
+		return checkKey([]byte(k))
+	}))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestParser_NextEntry_Docker(t *testing.T) {
+	p := NewWithOptions(strings.NewReader("A=\"1\" # x\n"), WithDialect(DialectDocker))
+	e, err := p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Val != `"1" # x` || e.Quotes != Unquoted || e.Comment != "" || string(e.Raw) != "A=\"1\" # x" {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+}
+
+func TestParse_Systemd(t *testing.T) {
+	buf := "# comment \\\n" +
+		"; comment\n" +
+		"  A = 1  \n" +
+		"NOSEP\n" +
+		"INLINE=foo # bar\n" +
+		"QUOTES=a\"b\"'c'\n" +
+		"JOINED=\"a\" 'b' c\\ \n" +
+		"SINGLE='a\\n\n" +
+		"b'\n" +
+		"DOUBLE=\"\\\"\\\\\\`\\$\\n\\q\\\n" +
+		"c\nd\"\n" +
+		"CONT=foo \\\n" +
+		"  bar  \n" +
+		"ESCAPE=\\a\\\\b\n" +
+		"EMPTY=\n" +
+		"EMPTYQ=''\n" +
+		"CRLF=x\r\n" +
+		"CRLFQ='x\r\n" +
+		"y'\r\n" +
+		"CRCONT=x\\\r\n" +
+		"LAST=\\"
+
+	env, err := ParseWithOptions(strings.NewReader(buf), WithDialect(DialectSystemd))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"A":      "1",
+		"INLINE": "foo # bar",
+		"QUOTES": `a"b"'c'`,
+		"JOINED": "abc ",
+		"SINGLE": "a\\n\nb",
+		"DOUBLE": "\"\\`$\\n\\qc\nd",
+		"CONT":   "foo   bar",
+		"ESCAPE": `a\b`,
+		"EMPTY":  "",
+		"EMPTYQ": "",
+		"CRLF":   "x",
+		"CRLFQ":  "x\r\ny",
+		"CRCONT": "x",
+		"LAST":   "",
+	}
+	if len(env) != len(expected) {
+		t.Errorf("expected %d keys but found %d: %#v", len(expected), len(env), env)
+	}
+	for k, v := range expected {
+		if found, ok := env[k]; !ok || found != v {
+			t.Errorf("expected %s=%q but found %q", k, v, found)
+		}
+	}
+}
+
+func TestParse_Systemd_Err(t *testing.T) {
+	cases := []struct {
+		name string
+		buf  string
+		line int
+		col  int
+		err  error
+	}{
+		{"Export", "A=1\nexport B=2\n", 2, 7, nil},
+		{"DottedKey", "A.B=1\n", 1, 2, nil},
+		{"EmptyKey", " =1\n", 1, 2, ErrEmptyKey},
+		{"UnterminatedSingle", "A='foo\n\nbar", 1, 3, ErrUnmatchedSingle},
+		{"UnterminatedDouble", "A=1\nB=\"foo\\\n", 2, 3, ErrUnmatchedDouble},
+		{"InvalidUTF8", "A=1\nB=a\xffb\n", 2, 4, ErrInvalidUTF8},
+		{"InvalidUTF8Continued", "A='a\nb\xff'\n", 2, 2, ErrInvalidUTF8},
+		{"NUL", "A=a\x00\n", 1, 4, nil},
+		{"BOM", "\xef\xbb\xbfA=1\n", 1, 1, nil},
+		{"BareCR", "A=1\rB=2\n", 1, 4, errBareCR},
+		{"BareCRComment", "# x\rB=2\n", 1, 4, errBareCR},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), WithDialect(DialectSystemd))
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T", err)
+			}
+			if perr.Line != c.line || perr.Column != c.col {
+				t.Errorf("expected error at %d:%d but found %d:%d: %v", c.line, c.col, perr.Line, perr.Column, err)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v but found %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParser_NextEntry_Systemd(t *testing.T) {
+	p := NewWithOptions(strings.NewReader("\nA='x\r\ny' z\r\nB=2\r\n"), WithDialect(DialectSystemd))
+	e, err := p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Val != "x\r\nyz" || e.Line != 2 || e.Offset != 1 || string(e.Raw) != "A='x\r\ny' z" || e.Quotes != SingleQuoted|Unquoted {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+
+	e, err = p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Pair != (Pair{"B", "2"}) || e.Line != 4 || e.Offset != 13 {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+}
+
+func TestParse_Dotenv(t *testing.T) {
+	buf := "# comment\n" +
+		"  A = 1  \n" +
+		"COLON: foo\n" +
+		"export EXPORTED=1\n" +
+		"lower.dotted-key=1\n" +
+		"HASH=a#b\n" +
+		"COMMENT=foo bar # baz\n" +
+		"LEADING=#foo\n" +
+		"SINGLE='a\\n $B' # comment\n" +
+		"DOUBLE=\"a\\nb\\r\\t\\\"\\\\\\$\\q\"#comment\n" +
+		"BACKTICK=`it's \"quoted\"`\n" +
+		"MULTI=\"a\r\n" +
+		"b\n" +
+		"\"\n" +
+		"TAB=a\tb\n" +
+		"EMPTY=\n" +
+		"EMPTYQ=''\n"
+
+	env, err := ParseWithOptions(strings.NewReader(buf), WithDialect(DialectDotenv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"A":                "1",
+		"COLON":            "foo",
+		"EXPORTED":         "1",
+		"lower.dotted-key": "1",
+		"HASH":             "a#b",
+		"COMMENT":          "foo bar",
+		"LEADING":          "",
+		"SINGLE":           `a\n $B`,
+		"DOUBLE":           "a\nb\r\t\"\\$\\q",
+		"BACKTICK":         `it's "quoted"`,
+		"MULTI":            "a\nb\n",
+		"TAB":              "a\tb",
+		"EMPTY":            "",
+		"EMPTYQ":           "",
+	}
+	if len(env) != len(expected) {
+		t.Errorf("expected %d keys but found %d: %#v", len(expected), len(env), env)
+	}
+	for k, v := range expected {
+		if found, ok := env[k]; !ok || found != v {
+			t.Errorf("expected %s=%q but found %q", k, v, found)
+		}
+	}
+}
+
+func TestParse_Dotenv_Interpolation(t *testing.T) {
+	buf := "A=foo\n" +
+		"B=$A-${A} # $A\n" +
+		"C=\"${A}\\$A\"\n" +
+		"D='$A'\n" +
+		"E=`$A`\n"
+
+	env, err := ParseWithOptions(strings.NewReader(buf), WithDialect(DialectDotenv), WithInterpolation(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"A": "foo",
+		"B": "foo-foo",
+		"C": "foo$A",
+		"D": "$A",
+		"E": "$A",
+	}
+	for k, v := range expected {
+		if found, ok := env[k]; !ok || found != v {
+			t.Errorf("expected %s=%q but found %q", k, v, found)
+		}
+	}
+}
+
+func TestParse_Dotenv_Err(t *testing.T) {
+	cases := []struct {
+		name string
+		buf  string
+		line int
+		col  int
+		err  error
+	}{
+		{"MissingSeparator", "A=1\n  B\n", 2, 3, ErrMissingSeparator},
+		{"EmptyKey", " =1\n", 1, 2, ErrEmptyKey},
+		{"KeySpace", "A B=1\n", 1, 2, nil},
+		{"KeySlash", "A/B=1\n", 1, 2, nil},
+		{"UnterminatedSingle", "A='foo\n\nbar", 1, 3, ErrUnmatchedSingle},
+		{"UnterminatedDouble", "A=1\nB=\"foo\\\"\n", 2, 3, ErrUnmatchedDouble},
+		{"UnterminatedBacktick", "A=`foo\n", 1, 3, ErrUnmatchedBacktick},
+		{"TrailingText", "A='a\nb' c\n", 2, 4, nil},
+		{"Interpolation", "A=\"\n${}\"\n", 2, 1, ErrInvalidVariable},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), WithDialect(DialectDotenv), WithInterpolation(nil))
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T", err)
+			}
+			if perr.Line != c.line || perr.Column != c.col {
+				t.Errorf("expected error at %d:%d but found %d:%d: %v", c.line, c.col, perr.Line, perr.Column, err)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v but found %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParser_NextEntry_Dotenv(t *testing.T) {
+	p := NewWithOptions(strings.NewReader("\nexport  A=`x\ny` # note\nB: 2\n"), WithDialect(DialectDotenv))
+	e, err := p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Val != "x\ny" || e.Line != 2 || e.Offset != 1 || string(e.Raw) != "export  A=`x\ny` # note" ||
+		e.Quotes != BacktickQuoted || !e.Export || e.Comment != "note" {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+
+	e, err = p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Pair != (Pair{"B", "2"}) || e.Line != 4 || e.Offset != 24 || e.Quotes != Unquoted {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Document is an editable representation of an environment file which
+// preserves blank lines, comments, and the original formatting of every line
+// that has not been modified.
+type Document struct {
+	lines []docLine
+}
+
+// docLine is a single line of a Document. Blank and comment lines have an
+// empty key.
+type docLine struct {
+	// raw line including its line ending
+	raw []byte
+
+	key    string
+	val    string
+	export bool
+
+	// indent is the whitespace before the key and comment the inline comment
+	// with the whitespace before it, kept when the pair is re-encoded
+	indent  []byte
+	comment []byte
+}
+
+// isPair returns true if the line contains a key/value pair.
+func (l *docLine) isPair() bool {
+	return l.key != ""
+}
+
+// newline returns the line ending of the line or def if it has none.
+func (l *docLine) newline(def []byte) []byte {
+	switch {
+	case bytes.HasSuffix(l.raw, crlf):
+		return crlf
+	case bytes.HasSuffix(l.raw, lf):
+		return lf
+	default:
+		return def
+	}
+}
+
+// encode replaces the raw line with the encoded key and value, keeping the
+// export prefix and line ending of the original line or using nl if it had
+// none.
+func (l *docLine) encode(nl []byte) {
+	nl = l.newline(nl)
+	raw := make([]byte, 0, len(l.indent)+len(exportPrefix)+len(l.key)+len(l.val)+len(l.comment)+4)
+	raw = append(raw, l.indent...)
+	if l.export {
+		raw = append(raw, exportPrefix...)
+	}
+	raw = appendPair(raw, Pair{Key: l.key, Val: l.val})
+	raw = append(raw[:len(raw)-1], l.comment...)
+	l.raw = append(raw, nl...)
+}
+
+var (
+	lf   = []byte{'\n'}
+	crlf = []byte{'\r', '\n'}
+)
+
+// ParseDocument parses environment variables from an io.Reader into a
+// Document or returns a ParseError.
+func ParseDocument(r io.Reader) (*Document, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, parseError(0, err)
+	}
+
+	d := &Document{}
+	for n, off := 1, 0; len(buf) > 0; n++ {
+		raw := buf
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			raw = buf[:i+1]
+		}
+		buf = buf[len(raw):]
+
+		ln := bytes.TrimSuffix(raw, lf)
+		info := lineInfo{}
+		k, v, err := parseLineWith(ln, &defaultOptions, nil, &info)
+		if err != nil {
+			return nil, lineParseError(n, off, bytes.TrimSuffix(ln, []byte{'\r'}), err)
+		}
+		off += len(raw)
+
+		line := docLine{raw: raw}
+		if len(k) > 0 {
+			line.key = string(k)
+			line.val = string(v)
+			line.export = hasExport(raw)
+			line.indent = ln[:len(ln)-len(bytes.TrimLeft(ln, " \t"))]
+			if info.commentAt > 0 {
+				start := len(bytes.TrimRight(ln[:info.commentAt], " \t"))
+				line.comment = bytes.TrimSuffix(ln[start:], []byte{'\r'})
+			}
+		}
+		d.lines = append(d.lines, line)
+	}
+
+	return d, nil
+}
+
+// hasExport returns true if the key in the raw line was prefixed with export.
+func hasExport(raw []byte) bool {
+	key := raw
+	if i := bytes.IndexByte(raw, '='); i >= 0 {
+		key = raw[:i]
+	}
+	key = bytes.TrimSpace(key)
+	return len(key) > len(exportPrefix) && bytes.HasPrefix(key, exportPrefix)
+}
+
+// newline returns the line ending of the first line which has one, or "\n"
+// if none do, matching Format.
+func (d *Document) newline() []byte {
+	for i := range d.lines {
+		if nl := d.lines[i].newline(nil); nl != nil {
+			return nl
+		}
+	}
+	return lf
+}
+
+// find returns the index of the last line defining key or -1.
+func (d *Document) find(key string) int {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		if d.lines[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// findValue returns the index of the last line defining key with a non-empty
+// value or -1. Like Parse, definitions with empty values are ignored.
+func (d *Document) findValue(key string) int {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		if d.lines[i].key == key && d.lines[i].val != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value of key and whether it was found. If a key is defined
+// more than once its last non-empty value is returned, matching Parse, and
+// keys only defined with empty values are not found.
+func (d *Document) Get(key string) (string, bool) {
+	i := d.findValue(key)
+	if i < 0 {
+		return "", false
+	}
+	return d.lines[i].val, true
+}
+
+// Keys returns the unique keys in the Document in order of their last
+// definition, matching ParsePairs.
+func (d *Document) Keys() []string {
+	pairs := d.Pairs()
+	keys := make([]string, len(pairs))
+	for i, p := range pairs {
+		keys[i] = p.Key
+	}
+	return keys
+}
+
+// Pairs returns the key/value pairs in the Document deduplicated the same way
+// as ParsePairs, skipping empty values.
+func (d *Document) Pairs() []Pair {
+	pairs := []Pair{}
+	for i := range d.lines {
+		l := &d.lines[i]
+		if !l.isPair() || d.findValue(l.key) != i {
+			continue
+		}
+		pairs = append(pairs, Pair{Key: l.key, Val: l.val})
+	}
+	return pairs
+}
+
+// Set the value of key. If key already exists its last definition is
+// rewritten in place, otherwise a new line is appended to the Document.
+func (d *Document) Set(key, val string) error {
+	if err := checkKey([]byte(key)); err != nil {
+		return fmt.Errorf("invalid key %q: %w", key, err)
+	}
+
+	if i := d.find(key); i >= 0 {
+		l := &d.lines[i]
+		if l.val != val {
+			l.val = val
+			l.encode(d.newline())
+		}
+		return nil
+	}
+
+	// Make sure the existing last line is terminated before appending
+	if n := len(d.lines); n > 0 && !bytes.HasSuffix(d.lines[n-1].raw, lf) {
+		last := &d.lines[n-1]
+		last.raw = append(last.raw[:len(last.raw):len(last.raw)], d.newline()...)
+	}
+
+	l := docLine{key: key, val: val}
+	l.encode(d.newline())
+	d.lines = append(d.lines, l)
+	return nil
+}
+
+// Delete every definition of key from the Document. Returns true if key was
+// found.
+func (d *Document) Delete(key string) bool {
+	found := false
+	lines := d.lines[:0]
+	for _, l := range d.lines {
+		if l.key == key {
+			found = true
+			continue
+		}
+		lines = append(lines, l)
+	}
+	d.lines = lines
+	return found
+}
+
+// Rename every definition of oldKey to newKey. An error is returned if oldKey
+// does not exist, newKey is invalid, or newKey already exists.
+func (d *Document) Rename(oldKey, newKey string) error {
+	if err := checkKey([]byte(newKey)); err != nil {
+		return fmt.Errorf("invalid key %q: %w", newKey, err)
+	}
+	if d.find(oldKey) < 0 {
+		return fmt.Errorf("key %q not found", oldKey)
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if d.find(newKey) >= 0 {
+		return fmt.Errorf("key %q already exists", newKey)
+	}
+
+	for i := range d.lines {
+		if l := &d.lines[i]; l.key == oldKey {
+			l.key = newKey
+			l.encode(d.newline())
+		}
+	}
+	return nil
+}
+
+// WriteTo writes the Document to w. Lines which have not been modified are
+// written exactly as they were read.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	total := int64(0)
+	for _, l := range d.lines {
+		n, err := w.Write(l.raw)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,257 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const docInput = `# Database settings
+
+export DB_HOST = localhost   # local only
+DB_PORT='5432'
+	DB_USER="admin"
+DB_HOST=db.internal
+EMPTY=
+`
+
+func parseDoc(t *testing.T, in string) *Document {
+	t.Helper()
+	d, err := ParseDocument(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return d
+}
+
+func docString(t *testing.T, d *Document) string {
+	t.Helper()
+	buf := bytes.Buffer{}
+	n, err := d.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes written but found %d", buf.Len(), n)
+	}
+	return buf.String()
+}
+
+func TestDocument_Unchanged(t *testing.T) {
+	cases := []string{
+		docInput,
+		"",
+		"\n\n",
+		"A=1",
+		"A=1\r\n# comment\r\nB=2\r\n",
+	}
+
+	for _, in := range cases {
+		d := parseDoc(t, in)
+		if out := docString(t, d); out != in {
+			t.Errorf("expected %q but found %q", in, out)
+		}
+	}
+}
+
+func TestDocument_Get(t *testing.T) {
+	d := parseDoc(t, docInput)
+
+	cases := []struct {
+		key   string
+		val   string
+		found bool
+	}{
+		{"DB_HOST", "db.internal", true},
+		{"DB_PORT", "5432", true},
+		{"DB_USER", "admin", true},
+		{"EMPTY", "", false},
+		{"MISSING", "", false},
+	}
+
+	for _, c := range cases {
+		v, ok := d.Get(c.key)
+		if v != c.val || ok != c.found {
+			t.Errorf("expected %s=(%q, %t) but found (%q, %t)", c.key, c.val, c.found, v, ok)
+		}
+	}
+
+	if exp, keys := "DB_PORT DB_USER DB_HOST", strings.Join(d.Keys(), " "); keys != exp {
+		t.Errorf("expected keys %q but found %q", exp, keys)
+	}
+}
+
+func TestDocument_Get_Empty(t *testing.T) {
+	buf := "FOO=bar\nFOO=\nBAR=1\n"
+	d := parseDoc(t, buf)
+	env, err := Parse(strings.NewReader(buf))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v, ok := d.Get("FOO"); !ok || v != env["FOO"] {
+		t.Errorf("expected FOO=%q but found (%q, %t)", env["FOO"], v, ok)
+	}
+	pairs, err := ParsePairs(strings.NewReader(buf))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found := d.Pairs(); !reflect.DeepEqual(found, pairs) {
+		t.Errorf("expected %v but found %v", pairs, found)
+	}
+}
+
+func TestDocument_Set(t *testing.T) {
+	d := parseDoc(t, docInput)
+
+	if err := d.Set("DB_PORT", "5432"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Set("DB_USER", "it's me"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Set("NEW", " x "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Set("1BAD", "x"); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	exp := `# Database settings
+
+export DB_HOST = localhost   # local only
+DB_PORT='5432'
+	DB_USER="it's me"
+DB_HOST=db.internal
+EMPTY=
+NEW=' x '
+`
+	if out := docString(t, d); out != exp {
+		t.Errorf("expected:\n%s\nfound:\n%s", exp, out)
+	}
+
+	// Setting a key defined more than once rewrites its last definition
+	d = parseDoc(t, docInput)
+	if err := d.Set("DB_HOST", "remote"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := docString(t, d)
+	if !strings.Contains(out, "export DB_HOST = localhost   # local only\n") {
+		t.Errorf("expected first definition to be unchanged:\n%s", out)
+	}
+	if !strings.Contains(out, "\nDB_HOST=remote\n") {
+		t.Errorf("expected last definition to be changed:\n%s", out)
+	}
+}
+
+func TestDocument_Set_Preserve(t *testing.T) {
+	d := parseDoc(t, "export A = 1 # one\r\nB=2")
+
+	if err := d.Set("A", "x"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Set("C", "3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exp, out := "export A=x # one\r\nB=2\r\nC=3\r\n", docString(t, d); out != exp {
+		t.Errorf("expected %q but found %q", exp, out)
+	}
+}
+
+func TestDocument_Set_Comment(t *testing.T) {
+	cases := []struct {
+		in  string
+		val string
+		out string
+	}{
+		{"  A=1 # one\n", "2", "  A=2 # one\n"},
+		{"A=1#one", "x#y", "A='x#y'#one\n"},
+		{"A=hi # note\n", "caf\u00e9", "A=caf\u00e9 # note\n"},
+		{"A=hi#note\n", "\U0001F525", "A=\U0001F525#note\n"},
+		{"\texport A='1'  #\r\n", "it's", "\texport A=\"it's\"  #\r\n"},
+	}
+
+	for _, c := range cases {
+		d := parseDoc(t, c.in)
+		if err := d.Set("A", c.val); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := docString(t, d)
+		if out != c.out {
+			t.Errorf("expected %q but found %q", c.out, out)
+		}
+		if v, _ := parseDoc(t, out).Get("A"); v != c.val {
+			t.Errorf("expected %q to parse to %q but found %q", out, c.val, v)
+		}
+	}
+}
+
+func TestDocument_Delete(t *testing.T) {
+	d := parseDoc(t, docInput)
+
+	if !d.Delete("DB_HOST") {
+		t.Errorf("expected DB_HOST to be deleted")
+	}
+	if d.Delete("DB_HOST") {
+		t.Errorf("expected DB_HOST to already be deleted")
+	}
+
+	exp := `# Database settings
+
+DB_PORT='5432'
+	DB_USER="admin"
+EMPTY=
+`
+	if out := docString(t, d); out != exp {
+		t.Errorf("expected:\n%s\nfound:\n%s", exp, out)
+	}
+}
+
+func TestDocument_Rename(t *testing.T) {
+	d := parseDoc(t, docInput)
+
+	if err := d.Rename("DB_HOST", "DATABASE_HOST"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Rename("MISSING", "OTHER"); err == nil {
+		t.Errorf("expected an error renaming a missing key")
+	}
+	if err := d.Rename("DB_PORT", "DB_USER"); err == nil {
+		t.Errorf("expected an error renaming to an existing key")
+	}
+	if err := d.Rename("DB_PORT", "bad key"); err == nil {
+		t.Errorf("expected an error renaming to an invalid key")
+	}
+
+	exp := `# Database settings
+
+export DATABASE_HOST=localhost   # local only
+DB_PORT='5432'
+	DB_USER="admin"
+DATABASE_HOST=db.internal
+EMPTY=
+`
+	if out := docString(t, d); out != exp {
+		t.Errorf("expected:\n%s\nfound:\n%s", exp, out)
+	}
+}
+
+func TestParseDocument_Err(t *testing.T) {
+	_, err := ParseDocument(strings.NewReader("A=1\n\nB\n"))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *envparse.ParseError but found %T", err)
+	}
+	if perr.Line != 3 || perr.Err != ErrMissingSeparator {
+		t.Errorf("expected %v on line 3 but found %v", ErrMissingSeparator, perr)
+	}
+}
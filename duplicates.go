@@ -0,0 +1,93 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DuplicatePolicy determines how a Parser handles keys defined more than once.
+type DuplicatePolicy int
+
+const (
+	// DuplicateLastWins returns every definition so the last value is used
+	// by Parse and ParsePairs.
+	DuplicateLastWins DuplicatePolicy = iota
+
+	// DuplicateFirstWins skips every definition after the first.
+	DuplicateFirstWins
+
+	// DuplicateError returns a ParseError wrapping a DuplicateKeyError for
+	// every definition after the first.
+	DuplicateError
+
+	// DuplicateWarn behaves like DuplicateLastWins but passes a
+	// DuplicateKeyError to the WithOnWarning function for every definition
+	// after the first.
+	DuplicateWarn
+)
+
+// DuplicateKeyError describes a key which was defined more than once.
+type DuplicateKeyError struct {
+	Key string
+
+	// FirstLine is the line the key was first defined on and Line the line
+	// it was repeated on.
+	FirstLine int
+	Line      int
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %s (first defined on line %d)", e.Key, e.FirstLine)
+}
+
+// checkDuplicate records the line key was defined on and applies the
+// duplicate policy. Returns false if the pair should be skipped.
+func (p *Parser) checkDuplicate(key []byte, line, off int) (bool, error) {
+	if p.seen == nil {
+		p.seen = make(map[string]int)
+	}
+
+	first, ok := p.seen[string(key)]
+	if !ok {
+		p.seen[string(key)] = line
+		return true, nil
+	}
+
+	derr := &DuplicateKeyError{Key: string(key), FirstLine: first, Line: line}
+	switch p.opts.duplicates {
+	case DuplicateFirstWins:
+		return false, nil
+	case DuplicateError:
+		perr := &ParseError{Line: line, Offset: off, Key: derr.Key, Err: derr}
+		text := bytes.TrimSuffix(p.text, []byte{'\r'})
+		perr.Text = string(text)
+		if col := keyColumn(text, key, p.opts.noExport); col >= 0 {
+			perr.Column = col + 1
+			perr.Offset += col
+		}
+		return false, perr
+	case DuplicateWarn:
+		if p.opts.onWarning != nil {
+			p.opts.onWarning(derr)
+		}
+		return true, nil
+	default:
+		return true, nil
+	}
+}
+
+// keyColumn returns the offset of key in the line text after any indentation
+// and export prefix, or -1 if it is not found there.
+func keyColumn(text, key []byte, noExport bool) int {
+	rest := bytes.TrimLeft(text, " \t")
+	if !noExport && bytes.HasPrefix(rest, exportPrefix) && !bytes.HasPrefix(rest, key) {
+		rest = bytes.TrimLeft(rest[len(exportPrefix):], " \t")
+	}
+	if !bytes.HasPrefix(rest, key) {
+		return -1
+	}
+	return len(text) - len(rest)
+}
@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dupInput = `A=1
+B=2
+A=3
+
+A=4
+`
+
+func TestParse_Duplicates(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy DuplicatePolicy
+		env    map[string]string
+		pairs  []Pair
+	}{
+		{"LastWins", DuplicateLastWins, map[string]string{"A": "4", "B": "2"}, []Pair{{"B", "2"}, {"A", "4"}}},
+		{"FirstWins", DuplicateFirstWins, map[string]string{"A": "1", "B": "2"}, []Pair{{"A", "1"}, {"B", "2"}}},
+		{"Warn", DuplicateWarn, map[string]string{"A": "4", "B": "2"}, []Pair{{"B", "2"}, {"A", "4"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(dupInput), WithDuplicates(c.policy))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(env) != len(c.env) {
+				t.Errorf("expected %d keys but found %d: %#v", len(c.env), len(env), env)
+			}
+			for k, v := range c.env {
+				if env[k] != v {
+					t.Errorf("expected %s=%q but found %q", k, v, env[k])
+				}
+			}
+
+			pairs, err := ParsePairsWithOptions(strings.NewReader(dupInput), WithDuplicates(c.policy))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(pairs) != len(c.pairs) {
+				t.Fatalf("expected %v but found %v", c.pairs, pairs)
+			}
+			for i := range c.pairs {
+				if pairs[i] != c.pairs[i] {
+					t.Errorf("expected %v but found %v", c.pairs[i], pairs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Duplicates_Error(t *testing.T) {
+	_, err := ParseWithOptions(strings.NewReader(dupInput), WithDuplicates(DuplicateError))
+
+	var derr *DuplicateKeyError
+	if !errors.As(err, &derr) {
+		t.Fatalf("expected a *DuplicateKeyError but found %T: %v", err, err)
+	}
+	if derr.Key != "A" || derr.FirstLine != 1 || derr.Line != 3 {
+		t.Errorf("expected A on lines 1 and 3 but found %#v", derr)
+	}
+
+	perr, ok := err.(*ParseError)
+	if !ok || perr.Line != 3 || perr.Key != "A" {
+		t.Errorf("expected a *ParseError on line 3 but found %#v", err)
+	}
+
+	// Every duplicate is reported when collecting errors
+	env, err := ParseWithOptions(strings.NewReader(dupInput), WithDuplicates(DuplicateError), WithCollectErrors())
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 2 || errs[0].Line != 3 || errs[1].Line != 5 {
+		t.Fatalf("expected duplicates on lines 3 and 5 but found %v", err)
+	}
+	if env["A"] != "1" {
+		t.Errorf("expected first value to be kept but found %q", env["A"])
+	}
+}
+
+func TestParse_Duplicates_Diagnostic(t *testing.T) {
+	buf := "export A=1\r\n  export A=\"x\r\ny\"\r\n"
+	_, err := ParseWithOptions(strings.NewReader(buf), WithDuplicates(DuplicateError), WithMultiline(), WithSource(".env"))
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError but found %T: %v", err, err)
+	}
+	if perr.Column != 10 || perr.Offset != 21 {
+		t.Errorf("expected column 10 and offset 21 but found %d and %d", perr.Column, perr.Offset)
+	}
+
+	exp := ".env:2:10: duplicate key A (first defined on line 1)\n\t  export A=\"x\n\t         ^\n"
+	if d := perr.Diagnostic(); d != exp {
+		t.Errorf("expected %q but found %q", exp, d)
+	}
+}
+
+func TestParse_Duplicates_Warn(t *testing.T) {
+	warnings := []*DuplicateKeyError{}
+	onWarning := func(err error) {
+		warnings = append(warnings, err.(*DuplicateKeyError))
+	}
+
+	_, err := ParseWithOptions(strings.NewReader(dupInput), WithDuplicates(DuplicateWarn), WithOnWarning(onWarning))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings but found %d", len(warnings))
+	}
+	if w := warnings[1]; w.Key != "A" || w.FirstLine != 1 || w.Line != 5 {
+		t.Errorf("expected A on lines 1 and 5 but found %#v", w)
+	}
+}
+
+func TestParse_Duplicates_Interpolation(t *testing.T) {
+	buf := "A=1\nA=2\nB=$A\n"
+	env, err := ParseWithOptions(strings.NewReader(buf), WithDuplicates(DuplicateFirstWins), WithInterpolation(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env["B"] != "1" {
+		t.Errorf("expected skipped duplicates to not be interpolated but found B=%q", env["B"])
+	}
+}
+
+func TestParse_Duplicates_EmptyValue(t *testing.T) {
+	buf := "A=1\nA=\nB=$A\n"
+	_, err := ParseWithOptions(strings.NewReader(buf), WithDuplicates(DuplicateError))
+	var derr *DuplicateKeyError
+	if !errors.As(err, &derr) || derr.Line != 2 {
+		t.Fatalf("expected a duplicate on line 2 but found %v", err)
+	}
+
+	env, err := ParseWithOptions(strings.NewReader(buf), WithDuplicates(DuplicateFirstWins), WithInterpolation(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := map[string]string{"A": "1", "B": "1"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %v but found %v", expected, env)
+	}
+}
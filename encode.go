@@ -0,0 +1,158 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// Encoder writes key/value pairs to an output stream in a form that Parse
+// will read back unchanged.
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes a single KEY=value line for the pair. The value is written
+// unquoted if possible, single quoted if it contains no single quotes or
+// control characters, and double quoted with JSON escapes otherwise.
+//
+// An error is returned without writing anything if the key would be rejected
+// by the parser. Empty values are written as KEY= which Parse skips unless
+// WithEmptyValues is used.
+func (e *Encoder) Encode(p Pair) error {
+	if err := checkKey([]byte(p.Key)); err != nil {
+		return fmt.Errorf("invalid key %q: %w", p.Key, err)
+	}
+
+	e.buf = appendPair(e.buf[:0], p)
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+// Marshal encodes env as a series of KEY=value lines sorted by key.
+func Marshal(env map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]Pair, len(keys))
+	for i, k := range keys {
+		pairs[i] = Pair{Key: k, Val: env[k]}
+	}
+	return MarshalPairs(pairs)
+}
+
+// MarshalPairs encodes pairs as a series of KEY=value lines in the order
+// given.
+func MarshalPairs(pairs []Pair) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := NewEncoder(&buf)
+	for _, p := range pairs {
+		if err := enc.Encode(p); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// appendPair appends the encoded KEY=value line for p to buf.
+func appendPair(buf []byte, p Pair) []byte {
+	buf = append(buf, p.Key...)
+	buf = append(buf, '=')
+	buf = appendValue(buf, p.Val)
+	return append(buf, '\n')
+}
+
+// appendValue appends v to buf using the minimal quoting required for it to
+// be parsed back unchanged.
+func appendValue(buf []byte, v string) []byte {
+	switch {
+	case canUnquote(v):
+		return append(buf, v...)
+	case canSingleQuote(v):
+		buf = append(buf, '\'')
+		buf = append(buf, v...)
+		return append(buf, '\'')
+	default:
+		return appendDoubleQuoted(buf, v)
+	}
+}
+
+// canUnquote returns true if v survives parsing without any quotes, whether
+// it ends the line or is followed by whitespace and an inline comment.
+func canUnquote(v string) bool {
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case c < 32:
+			return false
+		case c == '"', c == '\'', c == '#':
+			return false
+		}
+	}
+
+	// Leading and trailing whitespace is trimmed from unquoted values
+	if r, _ := utf8.DecodeRuneInString(v); unicode.IsSpace(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeLastRuneInString(v); unicode.IsSpace(r) {
+		return false
+	}
+	return true
+}
+
+// canSingleQuote returns true if v survives parsing inside single quotes.
+func canSingleQuote(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; c < 32 || c == '\'' {
+			return false
+		}
+	}
+	return true
+}
+
+// appendDoubleQuoted appends v to buf as a double quoted value, escaping
+// control characters, double quotes, and backslashes. All other bytes
+// (including multibyte characters) are passed through as-is.
+func appendDoubleQuoted(buf []byte, v string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 32 {
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+				continue
+			}
+			buf = append(buf, c)
+		}
+	}
+	return append(buf, '"')
+}
@@ -0,0 +1,136 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		val  string
+		out  string
+	}{
+		{"Simple", "bar", "bar"},
+		{"InnerSpaces", "bar baz", "bar baz"},
+		{"Backslash", `\text`, `\text`},
+		{"Unicode", "\U0001F525", "\U0001F525"},
+		{"LeadingSpace", " bar", "' bar'"},
+		{"TrailingTab", "bar\t", `"bar\t"`},
+		{"TrailingUnicodeSpace", "bar ", "'bar '"},
+		{"Comment", "bar # baz", "'bar # baz'"},
+		{"DoubleQuote", `say "hi"`, `'say "hi"'`},
+		{"SingleQuote", "it's", `"it's"`},
+		{"BothQuotes", `it's "hi"`, `"it's \"hi\""`},
+		{"Newline", "a\nb", `"a\nb"`},
+		{"CRLF", "\r\n", `"\r\n"`},
+		{"Control", "\x00\x1f", `"\u0000\u001f"`},
+		{"ControlAndBackslash", "\\\n", `"\\\n"`},
+		{"InvalidUTF8", "\xff\n", "\"\xff\\n\""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := NewEncoder(&buf).Encode(Pair{Key: "K", Val: c.val}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if exp := "K=" + c.out + "\n"; buf.String() != exp {
+				t.Errorf("expected %q but found %q", exp, buf.String())
+			}
+
+			env, err := Parse(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if env["K"] != c.val {
+				t.Errorf("expected %q but found %q", c.val, env["K"])
+			}
+		})
+	}
+}
+
+func TestEncoder_InvalidKey(t *testing.T) {
+	cases := []struct {
+		name string
+		key  string
+	}{
+		{"Empty", ""},
+		{"LeadingDigit", "1abc"},
+		{"Space", "A B"},
+		{"Newline", "A\nB"},
+		{"Equal", "A=B"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := NewEncoder(&buf).Encode(Pair{Key: c.key, Val: "x"})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expected nothing to be written but found %q", buf.String())
+			}
+		})
+	}
+
+	_, err := MarshalPairs([]Pair{{Key: "OK", Val: "1"}, {Key: "", Val: "2"}})
+	if !errors.Is(err, ErrEmptyKey) {
+		t.Errorf("expected %v but found %v", ErrEmptyKey, err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	env := map[string]string{
+		"B":       "2",
+		"A":       "1",
+		"FOO.BAR": " spaced ",
+	}
+
+	out, err := Marshal(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exp := "A=1\nB=2\nFOO.BAR=' spaced '\n"; string(out) != exp {
+		t.Errorf("expected %q but found %q", exp, string(out))
+	}
+
+	parsed, err := Parse(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for k, v := range env {
+		if parsed[k] != v {
+			t.Errorf("expected %s=%q but found %q", k, v, parsed[k])
+		}
+	}
+}
+
+func TestMarshalPairs_Order(t *testing.T) {
+	pairs := []Pair{{"Z", "1"}, {"A", "it's"}, {"M", "\t"}}
+
+	out, err := MarshalPairs(pairs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := ParsePairs(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed) != len(pairs) {
+		t.Fatalf("expected %d pairs but found %d: %#v", len(pairs), len(parsed), parsed)
+	}
+	for i := range pairs {
+		if parsed[i] != pairs[i] {
+			t.Errorf("expected %v but found %v", pairs[i], parsed[i])
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+// Quote is a set of quoting styles used by a value.
+type Quote uint8
+
+const (
+	// Unquoted is set if any part of the value was unquoted.
+	Unquoted Quote = 1 << iota
+
+	// SingleQuoted is set if any part of the value was single quoted.
+	SingleQuoted
+
+	// DoubleQuoted is set if any part of the value was double quoted.
+	DoubleQuoted
+
+	// Heredoc is set if the value was a heredoc.
+	Heredoc
+
+	// BacktickQuoted is set if any part of the value was quoted with
+	// backticks.
+	BacktickQuoted
+)
+
+// Entry is a key/value pair along with where and how it was defined.
+type Entry struct {
+	Pair
+
+	// Source is the name set with WithSource, if any.
+	Source string
+
+	// Line is the line the pair started on and Offset is the absolute byte
+	// offset of the start of that line.
+	Line   int
+	Offset int
+
+	// Raw is the text of every line the pair was parsed from, joined by
+	// newlines, without the final line ending.
+	Raw []byte
+
+	// Quotes is the set of quoting styles used by the value.
+	Quotes Quote
+
+	// Export is true if the key had an export prefix.
+	Export bool
+
+	// Comment is the text of a trailing inline comment without its # and
+	// surrounding whitespace.
+	Comment string
+}
+
+// lineInfo describes how a line was written.
+type lineInfo struct {
+	line    int
+	off     int
+	raw     []byte
+	quotes  Quote
+	export  bool
+	comment []byte
+
+	// commentAt is the offset of the # starting the comment within the
+	// line, or 0 if there is no comment
+	commentAt int
+}
+
+// NextEntry returns the next pair from the reader along with details of
+// where and how it was defined, or io.EOF at the end of input. Behaves the
+// same as Read otherwise.
+func (p *Parser) NextEntry() (Entry, error) {
+	info := lineInfo{}
+	kv, err := p.read(&info)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{
+		Pair:    kv,
+		Source:  p.opts.source,
+		Line:    info.line,
+		Offset:  info.off,
+		Raw:     info.raw,
+		Quotes:  info.quotes,
+		Export:  info.export,
+		Comment: string(info.comment),
+	}, nil
+}
@@ -0,0 +1,119 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParser_NextEntry(t *testing.T) {
+	buf := "# header\r\n" +
+		"export A = 1 # first\r\n" +
+		"\n" +
+		"B='x'\"y\" z\n" +
+		"C=\"multi\n" +
+		"line\" # trailing\n" +
+		"D<<EOF\n" +
+		"heredoc\n" +
+		"EOF\n" +
+		"E=☃"
+
+	p := NewWithOptions(strings.NewReader(buf), WithSource("test.env"), WithMultiline(), WithHeredocs())
+
+	expected := []Entry{
+		{
+			Pair:    Pair{"A", "1"},
+			Line:    2,
+			Offset:  10,
+			Raw:     []byte("export A = 1 # first"),
+			Quotes:  Unquoted,
+			Export:  true,
+			Comment: "first",
+		},
+		{
+			Pair:   Pair{"B", "xy z"},
+			Line:   4,
+			Offset: 33,
+			Raw:    []byte(`B='x'"y" z`),
+			Quotes: Unquoted | SingleQuoted | DoubleQuoted,
+		},
+		{
+			Pair:    Pair{"C", "multi\nline"},
+			Line:    5,
+			Offset:  44,
+			Raw:     []byte("C=\"multi\nline\" # trailing"),
+			Quotes:  DoubleQuoted,
+			Comment: "trailing",
+		},
+		{
+			Pair:   Pair{"D", "heredoc"},
+			Line:   7,
+			Offset: 70,
+			Raw:    []byte("D<<EOF\nheredoc\nEOF"),
+			Quotes: Heredoc,
+		},
+		{
+			Pair:   Pair{"E", "☃"},
+			Line:   10,
+			Offset: 89,
+			Raw:    []byte("E=☃"),
+			Quotes: Unquoted,
+		},
+	}
+
+	for _, exp := range expected {
+		e, err := p.NextEntry()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if e.Pair != exp.Pair {
+			t.Errorf("expected %v but found %v", exp.Pair, e.Pair)
+		}
+		if e.Source != "test.env" {
+			t.Errorf("%s: expected source test.env but found %q", exp.Key, e.Source)
+		}
+		if e.Line != exp.Line || e.Offset != exp.Offset {
+			t.Errorf("%s: expected line %d offset %d but found %d %d", exp.Key, exp.Line, exp.Offset, e.Line, e.Offset)
+		}
+		if string(e.Raw) != string(exp.Raw) {
+			t.Errorf("%s: expected raw %q but found %q", exp.Key, exp.Raw, e.Raw)
+		}
+		if e.Quotes != exp.Quotes {
+			t.Errorf("%s: expected quotes %b but found %b", exp.Key, exp.Quotes, e.Quotes)
+		}
+		if e.Export != exp.Export {
+			t.Errorf("%s: expected export %t but found %t", exp.Key, exp.Export, e.Export)
+		}
+		if e.Comment != exp.Comment {
+			t.Errorf("%s: expected comment %q but found %q", exp.Key, exp.Comment, e.Comment)
+		}
+	}
+
+	if _, err := p.NextEntry(); err != io.EOF {
+		t.Fatalf("expected io.EOF but found %v", err)
+	}
+}
+
+func TestParser_NextEntry_Empty(t *testing.T) {
+	p := NewWithOptions(strings.NewReader("export=\nA=''"), WithEmptyValues())
+
+	e, err := p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Key != "export" || e.Export || e.Quotes != 0 {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+
+	e, err = p.NextEntry()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Key != "A" || e.Quotes != SingleQuoted {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+}
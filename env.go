@@ -0,0 +1,200 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueError is returned when the value of a key cannot be converted to the
+// requested type. If the key was read from an input the ValueError is wrapped
+// in a ParseError with the line the key was defined on.
+type ValueError struct {
+	Key string
+	Val string
+	Err error
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("invalid value for %s: %v", e.Key, e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// Env provides typed access to parsed environment variables. Getters return
+// their default if the key is not defined and an error if its value cannot be
+// converted. The Must variants panic instead of returning an error.
+type Env struct {
+	entries map[string]Entry
+}
+
+// NewEnv returns an Env containing entries, such as those returned by
+// Loader.Load. Entries later in the slice take precedence over earlier ones
+// with the same key.
+func NewEnv(entries []Entry) *Env {
+	env := &Env{entries: make(map[string]Entry, len(entries))}
+	for _, e := range entries {
+		env.entries[e.Key] = e
+	}
+	return env
+}
+
+// ParseEnv parses environment variables from an io.Reader into an Env using a
+// Parser configured with the given options. If WithCollectErrors is enabled
+// an Env of the valid pairs is returned along with any ParseErrors.
+func ParseEnv(r io.Reader, opts ...Option) (*Env, error) {
+	entries, err := parseEntries(NewWithOptions(r, opts...))
+	if _, ok := err.(ParseErrors); ok {
+		return NewEnv(entries), err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewEnv(entries), nil
+}
+
+// parseEntries reads every entry from the parser. If errors are collected
+// the valid entries are returned along with the ParseErrors.
+func parseEntries(p *Parser) ([]Entry, error) {
+	entries := []Entry{}
+	for {
+		e, err := p.NextEntry()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if errs := p.Errors(); len(errs) > 0 {
+		return entries, errs
+	}
+	return entries, nil
+}
+
+// Lookup returns the value of key and whether it is defined.
+func (e *Env) Lookup(key string) (string, bool) {
+	entry, ok := e.entries[key]
+	return entry.Val, ok
+}
+
+// Get returns the value of key or def if it is not defined.
+func (e *Env) Get(key, def string) string {
+	if v, ok := e.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// GetInt returns the value of key as an int. Like Go integer literals the
+// value may have a base prefix such as 0x.
+func (e *Env) GetInt(key string, def int) (int, error) {
+	entry, ok := e.entries[key]
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(entry.Val, 0, strconv.IntSize)
+	if err != nil {
+		return 0, valueError(entry, err)
+	}
+	return int(n), nil
+}
+
+// GetBool returns the value of key as a bool. Accepted values are those of
+// strconv.ParseBool.
+func (e *Env) GetBool(key string, def bool) (bool, error) {
+	entry, ok := e.entries[key]
+	if !ok {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(entry.Val)
+	if err != nil {
+		return false, valueError(entry, err)
+	}
+	return b, nil
+}
+
+// GetDuration returns the value of key as a time.Duration such as 1m30s.
+func (e *Env) GetDuration(key string, def time.Duration) (time.Duration, error) {
+	entry, ok := e.entries[key]
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(entry.Val)
+	if err != nil {
+		return 0, valueError(entry, err)
+	}
+	return d, nil
+}
+
+// GetList returns the value of key split on sep. An empty value is an empty
+// list.
+func (e *Env) GetList(key, sep string, def []string) []string {
+	v, ok := e.Lookup(key)
+	if !ok {
+		return def
+	}
+	if v == "" {
+		return []string{}
+	}
+	return strings.Split(v, sep)
+}
+
+// MustInt is like GetInt but panics if the value cannot be converted.
+func (e *Env) MustInt(key string, def int) int {
+	n, err := e.GetInt(key, def)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// MustBool is like GetBool but panics if the value cannot be converted.
+func (e *Env) MustBool(key string, def bool) bool {
+	b, err := e.GetBool(key, def)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// MustDuration is like GetDuration but panics if the value cannot be
+// converted.
+func (e *Env) MustDuration(key string, def time.Duration) time.Duration {
+	d, err := e.GetDuration(key, def)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// valueError returns a ValueError for the entry wrapped in a ParseError if
+// the entry was read from an input.
+func valueError(e Entry, err error) error {
+	verr := &ValueError{Key: e.Key, Val: e.Val, Err: err}
+	if e.Line == 0 {
+		return verr
+	}
+
+	text := e.Raw
+	if i := bytes.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return &ParseError{
+		File:   e.Source,
+		Line:   e.Line,
+		Offset: e.Offset,
+		Key:    e.Key,
+		Text:   string(text),
+		Err:    verr,
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEnv = `
+NAME=app
+PORT=0x1f90
+DEBUG=true
+TIMEOUT=1m30s
+TAGS=a;b;c
+EMPTY=""
+BAD=eighty
+`
+
+func TestEnv(t *testing.T) {
+	env, err := ParseEnv(strings.NewReader(testEnv), WithEmptyValues())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v, ok := env.Lookup("NAME"); !ok || v != "app" {
+		t.Errorf("expected app but found %q %t", v, ok)
+	}
+	if v, ok := env.Lookup("EMPTY"); !ok || v != "" {
+		t.Errorf("expected empty value but found %q %t", v, ok)
+	}
+	if _, ok := env.Lookup("MISSING"); ok {
+		t.Errorf("expected MISSING to be undefined")
+	}
+	if v := env.Get("MISSING", "def"); v != "def" {
+		t.Errorf("expected def but found %q", v)
+	}
+
+	if n, err := env.GetInt("PORT", 80); err != nil || n != 8080 {
+		t.Errorf("expected 8080 but found %d: %v", n, err)
+	}
+	if n, err := env.GetInt("MISSING", 80); err != nil || n != 80 {
+		t.Errorf("expected 80 but found %d: %v", n, err)
+	}
+	if b, err := env.GetBool("DEBUG", false); err != nil || !b {
+		t.Errorf("expected true but found %t: %v", b, err)
+	}
+	if d, err := env.GetDuration("TIMEOUT", time.Second); err != nil || d != 90*time.Second {
+		t.Errorf("expected 1m30s but found %v: %v", d, err)
+	}
+	if d := env.MustDuration("MISSING", time.Second); d != time.Second {
+		t.Errorf("expected 1s but found %v", d)
+	}
+
+	lists := []struct {
+		key      string
+		expected []string
+	}{
+		{"TAGS", []string{"a", "b", "c"}},
+		{"EMPTY", []string{}},
+		{"MISSING", []string{"def"}},
+	}
+	for _, c := range lists {
+		if found := env.GetList(c.key, ";", []string{"def"}); !reflect.DeepEqual(found, c.expected) {
+			t.Errorf("%s: expected %q but found %q", c.key, c.expected, found)
+		}
+	}
+}
+
+func TestEnv_Err(t *testing.T) {
+	env, err := ParseEnv(strings.NewReader(testEnv), WithSource(".env"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	getters := map[string]func() error{
+		"GetInt":      func() error { _, err := env.GetInt("BAD", 0); return err },
+		"GetBool":     func() error { _, err := env.GetBool("BAD", false); return err },
+		"GetDuration": func() error { _, err := env.GetDuration("BAD", 0); return err },
+	}
+	for name, get := range getters {
+		t.Run(name, func(t *testing.T) {
+			err := get()
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected a *ParseError but found %T: %v", err, err)
+			}
+			if perr.File != ".env" || perr.Line != 8 || perr.Key != "BAD" || perr.Text != "BAD=eighty" {
+				t.Errorf("unexpected error: %#v", perr)
+			}
+
+			var verr *ValueError
+			if !errors.As(err, &verr) || verr.Key != "BAD" || verr.Val != "eighty" {
+				t.Errorf("expected a *ValueError for BAD but found %v", err)
+			}
+			if !strings.HasPrefix(err.Error(), ".env: error on line 8: invalid value for BAD: ") {
+				t.Errorf("unexpected message: %v", err)
+			}
+		})
+	}
+
+	// Entries which were not read from an input have no line
+	env = NewEnv([]Entry{{Pair: Pair{Key: "PORT", Val: "x"}, Source: "environment"}})
+	_, err = env.GetInt("PORT", 0)
+	if _, ok := err.(*ValueError); !ok {
+		t.Errorf("expected a *ValueError but found %T: %v", err, err)
+	}
+}
+
+func TestParseEnv_CollectErrors(t *testing.T) {
+	env, err := ParseEnv(strings.NewReader("A=1\nx\nB=2\n"), WithCollectErrors())
+	if errs, ok := err.(ParseErrors); !ok || len(errs) != 1 || errs[0].Line != 2 {
+		t.Fatalf("expected an error on line 2 but found %v", err)
+	}
+	if a, b := env.Get("A", ""), env.Get("B", ""); a != "1" || b != "2" {
+		t.Errorf("expected valid pairs to be kept but found A=%q B=%q", a, b)
+	}
+}
+
+func TestEnv_Must(t *testing.T) {
+	env := NewEnv([]Entry{
+		{Pair: Pair{Key: "A", Val: "1"}},
+		{Pair: Pair{Key: "A", Val: "x"}},
+	})
+
+	defer func() {
+		if _, ok := recover().(*ValueError); !ok {
+			t.Errorf("expected a *ValueError panic")
+		}
+	}()
+	env.MustInt("A", 0)
+	t.Errorf("expected MustInt to panic")
+}
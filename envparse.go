@@ -19,8 +19,10 @@ package envparse
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"unicode/utf16"
 	"unicode/utf8"
 )
@@ -34,26 +36,133 @@ var (
 	ErrIncompleteHex    = fmt.Errorf("incomplete hex sequence")
 	ErrIncompleteSur    = fmt.Errorf("incomplete Unicode surrogate pair")
 	ErrMultibyteEscape  = fmt.Errorf("multibyte characters disallowed in escape sequences")
+	ErrLineTooLong      = fmt.Errorf("line too long")
 )
 
 // ParseError is returned whenever the Parse function encounters an error. It
 // includes the line number and underlying error.
+//
+// When the position of the error within the line is known Column is its
+// 1-based byte column, otherwise Column is 0. Offset is the absolute byte
+// offset of the error in the input, or of the start of the line if the
+// Column is unknown. Key is set if the error occurred after the key was
+// parsed and Text is the line the error occurred on without its line ending.
+// File is the name set with WithSource, if any.
 type ParseError struct {
-	Line int
-	Err  error
+	File   string
+	Line   int
+	Column int
+	Offset int
+	Key    string
+	Text   string
+	Err    error
 }
 
 func (e *ParseError) Error() string {
+	prefix := ""
+	if e.File != "" {
+		prefix = e.File + ": "
+	}
 	if e.Line > 0 {
-		return fmt.Sprintf("error on line %d: %v", e.Line, e.Err)
+		return fmt.Sprintf("%serror on line %d: %v", prefix, e.Line, e.Err)
 	}
-	return fmt.Sprintf("error reading: %v", e.Err)
+	return fmt.Sprintf("%serror reading: %v", prefix, e.Err)
 }
 
 func (e *ParseError) Unwrap() error {
 	return e.Err
 }
 
+// Diagnostic renders the error in a compiler style format with the line's
+// text and a caret under the offending column, prefixed by the File if set:
+//
+//	.env:3:6: invalid escape sequence: "q"
+//		FOO="\q"
+//		     ^
+func (e *ParseError) Diagnostic() string {
+	buf := strings.Builder{}
+	switch {
+	case e.File != "" && e.Line > 0:
+		buf.WriteString(e.File + ":")
+	case e.File != "":
+		buf.WriteString(e.File + ": ")
+	}
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		fmt.Fprintf(&buf, "%d:%d: %v\n", e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		fmt.Fprintf(&buf, "%d: %v\n", e.Line, e.Err)
+	default:
+		fmt.Fprintf(&buf, "%v\n", e.Err)
+	}
+
+	if e.Column > 0 && e.Column <= len(e.Text)+1 {
+		buf.WriteByte('\t')
+		buf.WriteString(e.Text)
+		buf.WriteString("\n\t")
+
+		// Pad with tabs where the text has them so the caret lines up
+		for _, r := range e.Text[:e.Column-1] {
+			if r == '\t' {
+				buf.WriteByte('\t')
+			} else {
+				buf.WriteByte(' ')
+			}
+		}
+		buf.WriteString("^\n")
+	}
+	return buf.String()
+}
+
+// ParseErrors is returned when WithCollectErrors is enabled and one or more
+// lines could not be parsed.
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	buf := strings.Builder{}
+	fmt.Fprintf(&buf, "%d errors occurred:", len(e))
+	for _, err := range e {
+		buf.WriteString("\n\t* ")
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// Is returns true if any ParseError matches target so errors.Is matches
+// every error on every supported Go version.
+func (e ParseErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first ParseError which matches target so errors.As matches
+// every error on every supported Go version.
+func (e ParseErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns every ParseError for Go 1.20 and later.
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
 func parseError(line int, err error) error {
 	return &ParseError{
 		Line: line,
@@ -61,63 +170,270 @@ func parseError(line int, err error) error {
 	}
 }
 
+// lineParseError returns a ParseError for an error on a line of text starting
+// at the absolute offset off. If err is a lineError its position is used.
+func lineParseError(line, off int, text []byte, err error) error {
+	perr := &ParseError{
+		Line:   line,
+		Offset: off,
+		Text:   string(text),
+		Err:    err,
+	}
+	if lerr, ok := err.(*lineError); ok {
+		perr.Column = lerr.off + 1
+		perr.Offset += lerr.off
+		perr.Key = string(lerr.key)
+		perr.Err = lerr.err
+	}
+	return perr
+}
+
+// lineError is an error at a byte offset within a line and the key being
+// parsed if known.
+type lineError struct {
+	off int
+	key []byte
+	err error
+}
+
+func (e *lineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
+func errAt(off int, key []byte, err error) error {
+	return &lineError{
+		off: off,
+		key: key,
+		err: err,
+	}
+}
+
 // Parser incrementally parses environment variables from an input.
 type Parser struct {
-	i int
-	s *bufio.Scanner
+	i    int
+	s    *bufio.Scanner
+	opts options
+
+	// off is the offset of the current line and next the offset of the line
+	// following it
+	off  int
+	next int
+
+	// tooLong is set when a line exceeds the configured maximum size
+	tooLong bool
+
+	// errs are the errors of lines skipped when errors are collected
+	errs ParseErrors
+
+	// seen is the line each key was first defined on when checking for
+	// duplicates
+	seen map[string]int
+
+	// text is a copy of the first line of the current pair, kept to report
+	// duplicates as errors
+	text []byte
+
+	// ex is set when interpolation is enabled
+	ex *expander
 }
 
 // New environment variable Parser from an input reader.
 func New(r io.Reader) *Parser {
-	return &Parser{
+	p := &Parser{
 		s: bufio.NewScanner(r),
 	}
+	p.s.Split(p.scanLines)
+	return p
+}
+
+// scanLines wraps bufio.ScanLines to track the offset of each line. Carriage
+// returns are kept for DialectSystemd which treats them differently within
+// quotes.
+func (p *Parser) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	split := bufio.ScanLines
+	if p.opts.dialect == DialectSystemd {
+		split = scanRawLines
+	}
+	advance, token, err := split(data, atEOF)
+	if token != nil {
+		p.off = p.next
+		p.next += advance
+	}
+	return advance, token, err
 }
 
 // Next returns the next key and value from the reader. May return duplicates
 // if the same key occurs more than once in the input.
 //
 // An empty pair indicates end of input. Blank lines in the input are skipped.
+// Use Read to distinguish the end of input from pairs with empty values when
+// WithEmptyValues is enabled.
 func (p *Parser) Next() (Pair, error) {
-	for p.s.Scan() {
-		p.i++
-		k, v, err := parseLine(p.s.Bytes())
+	kv, err := p.Read()
+	if err == io.EOF {
+		return emptyPair, nil
+	}
+	return kv, err
+}
+
+// Read returns the next key and value from the reader or io.EOF at the end of
+// input. May return duplicates if the same key occurs more than once in the
+// input.
+//
+// Blank lines in the input are skipped, as are keys with empty values unless
+// WithEmptyValues is enabled.
+func (p *Parser) Read() (Pair, error) {
+	return p.read(nil)
+}
+
+// read returns the next pair, populating info with details of the line it
+// was read from if info is non-nil.
+func (p *Parser) read(info *lineInfo) (Pair, error) {
+	for p.scan() {
+		line, off := p.i, p.off
+		if p.opts.duplicates == DuplicateError {
+			p.text = append(p.text[:0], p.s.Bytes()...)
+		}
+		k, v, err := p.parseLine(p.s.Bytes(), info)
 		if err != nil {
-			return emptyPair, parseError(p.i, err)
+			if err := p.skip(err); err != nil {
+				return emptyPair, err
+			}
+			continue
 		}
 
-		if len(v) > 0 {
+		// Redefinitions with empty values are still duplicates even when the
+		// empty value itself is skipped
+		if len(k) > 0 && p.opts.duplicates != DuplicateLastWins {
+			keep, err := p.checkDuplicate(k, line, off)
+			if err != nil {
+				if err := p.skip(err); err != nil {
+					return emptyPair, err
+				}
+				continue
+			}
+			if !keep {
+				continue
+			}
+		}
+
+		if p.ex != nil && len(k) > 0 {
+			p.ex.vars[string(k)] = string(v)
+		}
+
+		if ok := len(v) > 0 || (len(k) > 0 && p.opts.emptyValues); ok {
+			if info != nil {
+				info.line, info.off = line, off
+			}
 			return Pair{Key: string(k), Val: string(v)}, nil
 		}
 	}
 
-	if err := p.s.Err(); err != nil {
-		return emptyPair, parseError(p.i, err)
+	if err := p.scanErr(); err != nil {
+		if perr, ok := err.(*ParseError); ok {
+			perr.File = p.opts.source
+		}
+		return emptyPair, err
+	}
+
+	return emptyPair, io.EOF
+}
+
+// Errors returns the errors of every line skipped so far when WithCollectErrors
+// is enabled.
+func (p *Parser) Errors() ParseErrors {
+	return p.errs
+}
+
+// skip decides whether the line which caused err should be skipped. Returns
+// nil if parsing should continue or the error to return otherwise.
+func (p *Parser) skip(err error) error {
+	if perr, ok := err.(*ParseError); ok {
+		perr.File = p.opts.source
+	}
+	if !p.opts.collectErrors && p.opts.onError == nil {
+		return err
+	}
+
+	// Errors reading the input are never recoverable
+	perr, ok := err.(*ParseError)
+	if !ok || p.tooLong || p.s.Err() != nil {
+		return err
+	}
+
+	if p.opts.onError != nil {
+		if err := p.opts.onError(perr); err != nil {
+			return err
+		}
+	}
+	if p.opts.collectErrors {
+		p.errs = append(p.errs, perr)
+	}
+	return nil
+}
+
+// scan advances to the next line of input. Returns false at the end of input
+// or if an error occurred which is returned by scanErr.
+func (p *Parser) scan() bool {
+	if !p.s.Scan() {
+		return false
 	}
+	p.i++
 
-	// EOF
-	return emptyPair, nil
+	if p.opts.maxLineSize > 0 && len(bytes.TrimSuffix(p.s.Bytes(), []byte{'\r'})) > p.opts.maxLineSize {
+		p.tooLong = true
+		return false
+	}
+	return true
+}
+
+// scanErr returns the error which stopped scan, if any, as a ParseError.
+func (p *Parser) scanErr() error {
+	if p.tooLong {
+		return &ParseError{Line: p.i, Offset: p.off, Err: ErrLineTooLong}
+	}
+
+	err := p.s.Err()
+	switch err {
+	case nil:
+		return nil
+	case bufio.ErrTooLong:
+		// The line which was too long was never returned by Scan
+		return &ParseError{Line: p.i + 1, Offset: p.next, Err: ErrLineTooLong}
+	default:
+		return parseError(p.i, err)
+	}
 }
 
 // Parse environment variables from an io.Reader into a map or return a
 // ParseError.
 func Parse(r io.Reader) (map[string]string, error) {
+	return parse(New(r))
+}
+
+// parse environment variables from a Parser into a map.
+func parse(parser *Parser) (map[string]string, error) {
 	env := make(map[string]string)
-	parser := New(r)
 
 	for {
-		kv, err := parser.Next()
+		kv, err := parser.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		if kv == emptyPair {
-			break
-		}
-
 		env[kv.Key] = kv.Val
 	}
 
+	if errs := parser.Errors(); len(errs) > 0 {
+		return env, errs
+	}
 	return env, nil
 }
 
@@ -133,19 +449,23 @@ type Pair struct {
 // Unlike calling Parser(r).Next() in a loop, this ParsePairs deduplicates
 // repeated keys and uses their last position and value.
 func ParsePairs(r io.Reader) ([]Pair, error) {
+	return parsePairs(New(r))
+}
+
+// parsePairs parses environment variables from a Parser into a slice of
+// deduplicated key/value pairs.
+func parsePairs(parser *Parser) ([]Pair, error) {
 	env := []Pair{}
-	parser := New(r)
 
 	for {
-		kv, err := parser.Next()
+		kv, err := parser.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		if kv == emptyPair {
-			break
-		}
-
 		for i, p := range env {
 			// Remove previous entry for this key
 			if p.Key == kv.Key {
@@ -156,6 +476,9 @@ func ParsePairs(r io.Reader) ([]Pair, error) {
 		env = append(env, kv)
 	}
 
+	if errs := parser.Errors(); len(errs) > 0 {
+		return env, errs
+	}
 	return env, nil
 }
 
@@ -170,7 +493,6 @@ const (
 var (
 	emptyPair    = Pair{}
 	empty        = []byte{}
-	separator    = []byte{'='}
 	exportPrefix = []byte("export ")
 )
 
@@ -178,50 +500,51 @@ var (
 //
 // Empty lines are returned as zero length slices
 func parseLine(ln []byte) ([]byte, []byte, error) {
-	ln = bytes.TrimSpace(ln)
-	if len(ln) == 0 || ln[0] == '#' {
-		return empty, empty, nil
+	k, v, err := parseLineWith(ln, &defaultOptions, nil, nil)
+	if lerr, ok := err.(*lineError); ok {
+		return k, v, lerr.err
 	}
+	return k, v, err
+}
 
-	parts := bytes.SplitN(ln, separator, 2)
-	if len(parts) != 2 {
-		return nil, nil, ErrMissingSeparator
+// parseLineWith parses the given line like parseLine using the given options,
+// expanding variable references if ex is non-nil. Errors are returned as
+// lineErrors with their offset in ln. If info is non-nil it is populated with
+// the quoting, export prefix, and comment of the line.
+func parseLineWith(ln []byte, o *options, ex *expander, info *lineInfo) ([]byte, []byte, error) {
+	if info != nil {
+		*info = lineInfo{}
 	}
 
-	// Trim whitespace
-	key, value := bytes.TrimSpace(parts[0]), bytes.TrimSpace(parts[1])
-
-	// Ensure key is of the form [A-Za-z][A-Za-z0-9_]? with an optional
-	// leading 'export ', but only trim leading export if there's another
-	// key name.
-	if len(key) > len(exportPrefix) {
-		key = bytes.TrimPrefix(key, exportPrefix)
-	}
-	if len(key) == 0 {
-		return nil, nil, ErrEmptyKey
+	// Offsets of subslices are relative to the original line
+	orig := ln
+	at := func(b []byte) int {
+		return cap(orig) - cap(b)
 	}
-	if key[0] < 'A' {
-		return nil, nil, fmt.Errorf("key must start with [A-Za-z_] but found %q", key[0])
+
+	ln = bytes.TrimSpace(ln)
+	if len(ln) == 0 || ln[0] == '#' {
+		return empty, empty, nil
 	}
-	if key[0] > 'Z' && key[0] < 'a' && key[0] != '_' {
-		return nil, nil, fmt.Errorf("key must start with [A-Za-z_] but found %q", key[0])
+
+	sep := bytes.IndexByte(ln, '=')
+	if sep < 0 {
+		return nil, nil, errAt(at(ln), nil, ErrMissingSeparator)
 	}
-	if key[0] > 'z' {
-		return nil, nil, fmt.Errorf("key must start with [A-Za-z_] but found %q", key[0])
+
+	if info != nil {
+		info.export = hasExport(ln[:sep]) && !o.noExport
 	}
 
-	for _, v := range key[1:] {
-		switch {
-		case v == '_':
-		case v == '.':
-		case v == '/':
-		case v >= 'A' && v <= 'Z':
-		case v >= 'a' && v <= 'z':
-		case v >= '0' && v <= '9':
-		default:
-			return nil, nil, fmt.Errorf("key characters must be [A-Za-z0-9/_.] but found %q", v)
+	// Trim whitespace
+	key, bad, err := o.cleanKey(ln[:sep])
+	if err != nil {
+		if len(key) == 0 {
+			return nil, nil, errAt(at(ln[sep:]), nil, err)
 		}
+		return nil, nil, errAt(at(key)+bad, nil, err)
 	}
+	value := bytes.TrimSpace(ln[sep+1:])
 
 	// Evaluate the value
 	if len(value) == 0 {
@@ -238,24 +561,58 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 	// Parser State
 	mode := normalMode
 
+	// Offsets of the value, the current quote, and the current escape sequence
+	valOff := at(value)
+	quoteStart := 0
+	escStart := 0
+
 	for i := 0; i < len(value); i++ {
 		v := value[i]
 
-		// Control characters are always an error
-		if v < 32 {
-			return nil, nil, fmt.Errorf("0x%0.2x is an invalid value character", v)
+		// Control characters are always an error except for newlines within
+		// quotes when multiline values are enabled
+		if v == '\n' && o.multiline && (mode == doubleQuote || mode == singleQuote) {
+			newv[newi] = v
+			newi++
+			lastSig = newi
+			continue
+		}
+		if v < 32 && !o.controlChars {
+			return nil, nil, errAt(valOff+i, key, fmt.Errorf("0x%0.2x is an invalid value character", v))
 		}
 
 		// High bit set means it is part of a multibyte character, pass
 		// it through as only ASCII characters have special meaning.
 		if v > 127 {
 			if mode == escapeMode {
-				return nil, nil, ErrMultibyteEscape
+				return nil, nil, errAt(valOff+i, key, ErrMultibyteEscape)
+			}
+			if info != nil && mode == normalMode {
+				info.quotes |= Unquoted
 			}
+
 			// All multibyte characters are significant
-			lastSig = newi
 			newv[newi] = v
 			newi++
+			lastSig = newi
+			continue
+		}
+
+		// Expand variable references outside of single quotes
+		if v == '$' && ex != nil && (mode == normalMode || mode == doubleQuote) {
+			exp, n, err := ex.expand(value[i:])
+			if err != nil {
+				return nil, nil, errAt(valOff+i, key, err)
+			}
+			if info != nil && mode == normalMode {
+				info.quotes |= Unquoted
+			}
+			newv = growValue(newv, newi, len(exp)+len(value)-i)
+			newi += copy(newv[newi:], exp)
+			i += n - 1
+			if len(exp) > 0 {
+				lastSig = newi
+			}
 			continue
 		}
 
@@ -264,20 +621,39 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 			switch v {
 			case '"':
 				mode = doubleQuote
+				quoteStart = i
+				if info != nil {
+					info.quotes |= DoubleQuoted
+				}
 			case '\'':
 				mode = singleQuote
-			case '#':
-				// Start of a comment, nothing left to parse
-				return key, newv[:lastSig], nil
+				quoteStart = i
+				if info != nil {
+					info.quotes |= SingleQuoted
+				}
 			case ' ', '\t':
 				// Make sure whitespace doesn't get tracked
 				newv[newi] = v
 				newi++
+			case '#':
+				if !o.noInlineComments {
+					// Start of a comment, nothing left to parse
+					if info != nil {
+						info.comment = bytes.TrimSpace(value[i+1:])
+						info.commentAt = valOff + i
+					}
+					return key, newv[:lastSig], nil
+				}
+				fallthrough
 			default:
 				// Add the character to the new value
 				newv[newi] = v
 				newi++
 
+				if info != nil {
+					info.quotes |= Unquoted
+				}
+
 				// Track last non-WS char for trimming on trailing comments
 				lastSig = newi
 			}
@@ -287,6 +663,7 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 				mode = normalMode
 			case '\\':
 				mode = escapeMode
+				escStart = i
 			default:
 				// Add the character to the new value
 				newv[newi] = v
@@ -304,6 +681,11 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 				newv[newi] = v
 			case '/':
 				newv[newi] = v
+			case '$':
+				if ex == nil {
+					return nil, nil, errAt(valOff+escStart, key, fmt.Errorf("invalid escape sequence: %q", string(v)))
+				}
+				newv[newi] = v
 			case 'b':
 				newv[newi] = '\b'
 			case 'f':
@@ -318,7 +700,7 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 				// Parse-ahead to capture unicode
 				r, err := h2r(value[i+1:])
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, errAt(valOff+escStart, key, err)
 				}
 
 				// Bump index by width of hex chars
@@ -328,16 +710,16 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 				if utf16.IsSurrogate(r) {
 					if len(value) < i+6 {
 						//TODO Use replacement character instead?
-						return nil, nil, ErrIncompleteSur
+						return nil, nil, errAt(valOff+escStart, key, ErrIncompleteSur)
 					}
 					if value[i+1] != '\\' || value[i+2] != 'u' {
 						//TODO Use replacement character instead?
-						return nil, nil, ErrIncompleteSur
+						return nil, nil, errAt(valOff+escStart, key, ErrIncompleteSur)
 					}
 
 					r2, err := h2r(value[i+3:])
 					if err != nil {
-						return nil, nil, err
+						return nil, nil, errAt(valOff+i+1, key, err)
 					}
 
 					// Bump index by width of \uXXXX
@@ -348,7 +730,7 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 				n := utf8.EncodeRune(newv[newi:], r)
 				newi += n - 1 // because it's incremented outside the switch
 			default:
-				return nil, nil, fmt.Errorf("invalid escape sequence: %q", string(v))
+				return nil, nil, errAt(valOff+escStart, key, fmt.Errorf("invalid escape sequence: %q", string(v)))
 			}
 			// Add the character to the new value
 			newi++
@@ -380,16 +762,80 @@ func parseLine(ln []byte) ([]byte, []byte, error) {
 		// All escape sequences are complete and all quotes are matched
 		return key, newv[:newi], nil
 	case doubleQuote:
-		return nil, nil, ErrUnmatchedDouble
+		return nil, nil, errAt(valOff+quoteStart, key, ErrUnmatchedDouble)
 	case singleQuote:
-		return nil, nil, ErrUnmatchedSingle
+		return nil, nil, errAt(valOff+quoteStart, key, ErrUnmatchedSingle)
 	case escapeMode:
-		return nil, nil, ErrIncompleteEscape
+		return nil, nil, errAt(valOff+escStart, key, ErrIncompleteEscape)
 	default:
 		panic(fmt.Errorf("BUG: invalid mode: %v", mode))
 	}
 }
 
+// cleanKey trims whitespace and an optional export prefix from a raw key and
+// returns an error if it is not a valid key name. On error the trimmed key and
+// the index of the offending character within it are returned.
+func (o *options) cleanKey(key []byte) ([]byte, int, error) {
+	key = bytes.TrimSpace(key)
+
+	// Ensure key is of the form [A-Za-z][A-Za-z0-9_]? with an optional
+	// leading 'export ', but only trim leading export if there's another
+	// key name.
+	if len(key) > len(exportPrefix) && !o.noExport {
+		key = bytes.TrimPrefix(key, exportPrefix)
+	}
+	if len(key) == 0 {
+		return key, 0, ErrEmptyKey
+	}
+	if o.checkKey != nil {
+		if err := o.checkKey(string(key)); err != nil {
+			return key, 0, err
+		}
+		return key, 0, nil
+	}
+	if i, err := checkKeyAt(key); err != nil {
+		return key, i, err
+	}
+	return key, 0, nil
+}
+
+// checkKey returns an error if key is not a valid key name.
+func checkKey(key []byte) error {
+	_, err := checkKeyAt(key)
+	return err
+}
+
+// checkKeyAt returns an error and the index of the offending character if key
+// is not a valid key name.
+func checkKeyAt(key []byte) (int, error) {
+	if len(key) == 0 {
+		return 0, ErrEmptyKey
+	}
+	if key[0] < 'A' {
+		return 0, fmt.Errorf("key must start with [A-Za-z_] but found %q", key[0])
+	}
+	if key[0] > 'Z' && key[0] < 'a' && key[0] != '_' {
+		return 0, fmt.Errorf("key must start with [A-Za-z_] but found %q", key[0])
+	}
+	if key[0] > 'z' {
+		return 0, fmt.Errorf("key must start with [A-Za-z_] but found %q", key[0])
+	}
+
+	for i, v := range key[1:] {
+		switch {
+		case v == '_':
+		case v == '.':
+		case v == '/':
+		case v >= 'A' && v <= 'Z':
+		case v >= 'a' && v <= 'z':
+		case v >= '0' && v <= '9':
+		default:
+			return i + 1, fmt.Errorf("key characters must be [A-Za-z0-9/_.] but found %q", v)
+		}
+	}
+	return 0, nil
+}
+
 // convert hex characters into a rune
 func h2r(buf []byte) (rune, error) {
 	if len(buf) < 4 {
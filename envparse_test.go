@@ -4,8 +4,11 @@
 package envparse
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 )
@@ -68,6 +71,76 @@ A=4
 
 }
 
+// TestParser_Read asserts that Read returns io.EOF at the end of input and
+// that empty values are only returned when enabled.
+func TestParser_Read(t *testing.T) {
+	buf := `A=1
+B=
+C=""
+D='' # empty
+`
+
+	cases := []struct {
+		name string
+		opts []Option
+		exp  []Pair
+	}{
+		{"Default", nil, []Pair{{"A", "1"}}},
+		{"EmptyValues", []Option{WithEmptyValues()}, []Pair{{"A", "1"}, {"B", ""}, {"C", ""}, {"D", ""}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := NewWithOptions(bytes.NewBufferString(buf), c.opts...)
+			for _, exp := range c.exp {
+				kv, err := p.Read()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if kv != exp {
+					t.Fatalf("expected %v but found %v", exp, kv)
+				}
+			}
+
+			kv, err := p.Read()
+			if err != io.EOF {
+				t.Fatalf("expected io.EOF but found %v (%v)", err, kv)
+			}
+
+			// Next still signals EOF with an empty pair
+			kv, err = p.Next()
+			if err != nil || kv != emptyPair {
+				t.Fatalf("expected empty pair but found %v (%v)", kv, err)
+			}
+		})
+	}
+}
+
+// TestParse_EmptyValues asserts that keys declared with empty values are
+// kept by Parse and ParsePairs when enabled.
+func TestParse_EmptyValues(t *testing.T) {
+	buf := "A=1\nB=\nA=\n"
+
+	env, err := ParseWithOptions(bytes.NewBufferString(buf), WithEmptyValues())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := env["A"]; !ok || v != "" {
+		t.Errorf("expected A to be overridden with an empty value but found %q", v)
+	}
+	if _, ok := env["B"]; !ok {
+		t.Errorf("expected B to be set")
+	}
+
+	pairs, err := ParsePairsWithOptions(bytes.NewBufferString(buf), WithEmptyValues())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := []Pair{{"B", ""}, {"A", ""}}; len(pairs) != 2 || pairs[0] != exp[0] || pairs[1] != exp[1] {
+		t.Errorf("expected %v but found %v", exp, pairs)
+	}
+}
+
 func TestParse_OK(t *testing.T) {
 	buf := `# Start of file
 
@@ -181,6 +254,250 @@ func TestParse_Err(t *testing.T) {
 	}
 }
 
+// TestParse_Err_Position asserts that ParseErrors include the column, offset,
+// key, and text of the error.
+func TestParse_Err_Position(t *testing.T) {
+	cases := []struct {
+		name string
+		buf  string
+		opts []Option
+		line int
+		col  int
+		off  int
+		key  string
+		text string
+	}{
+		{"MissingEqual", "A=1\r\n  x\n", nil, 2, 3, 7, "", "  x"},
+		{"EmptyKey", "A=1\n =x", nil, 2, 2, 5, "", " =x"},
+		{"InvalidKeyChar", "export FOO-BAR=1", nil, 1, 11, 10, "", "export FOO-BAR=1"},
+		{"InvalidKeyStart", "A=1\n\t1A=1", nil, 2, 2, 5, "", "\t1A=1"},
+		{"InvalidEscape", `FOO = "ok\q"`, nil, 1, 10, 9, "FOO", `FOO = "ok\q"`},
+		{"InvalidHex", `FOO="\u12Z4"`, nil, 1, 6, 5, "FOO", `FOO="\u12Z4"`},
+		{"UnmatchedDouble", `FOO=a "b`, nil, 1, 7, 6, "FOO", `FOO=a "b`},
+		{"UnmatchedSingle", "FOO='a' 'b", nil, 1, 9, 8, "FOO", "FOO='a' 'b"},
+		{"IncompleteEscape", `FOO="\`, nil, 1, 6, 5, "FOO", `FOO="\`},
+		{"ControlChar", "FOO=a\x01", nil, 1, 6, 5, "FOO", "FOO=a\x01"},
+		{"Undefined", "A=1\nB=x${C}", []Option{WithInterpolation(nil), WithStrictInterpolation()}, 2, 4, 7, "B", "B=x${C}"},
+		{"MultilineUnmatched", "A=1\nB='x\ny\n", []Option{WithMultiline()}, 2, 3, 6, "B", "B='x"},
+		{"MultilineEscape", "A=\"x\r\n  \\q\"", []Option{WithMultiline()}, 2, 3, 8, "A", `  \q"`},
+		{"HeredocKey", "A-B<<EOF\nEOF", []Option{WithHeredocs()}, 1, 2, 1, "", "A-B<<EOF"},
+		{"HeredocUnterminated", "A=1\nB<<EOF\n", []Option{WithHeredocs()}, 2, 2, 5, "B", "B<<EOF"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseWithOptions(strings.NewReader(c.buf), c.opts...)
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T: %v", err, err)
+			}
+			if perr.Line != c.line || perr.Column != c.col || perr.Offset != c.off {
+				t.Errorf("expected %d:%d (offset %d) but found %d:%d (offset %d): %v",
+					c.line, c.col, c.off, perr.Line, perr.Column, perr.Offset, err)
+			}
+			if perr.Key != c.key {
+				t.Errorf("expected key %q but found %q", c.key, perr.Key)
+			}
+			if perr.Text != c.text {
+				t.Errorf("expected text %q but found %q", c.text, perr.Text)
+			}
+		})
+	}
+}
+
+func TestParseError_Diagnostic(t *testing.T) {
+	_, err := Parse(strings.NewReader("A=1\n\tFOO=\"☃\\q\"\n"))
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *envparse.ParseError but found %T: %v", err, err)
+	}
+
+	exp := "2:10: invalid escape sequence: \"q\"\n" +
+		"\t\tFOO=\"☃\\q\"\n" +
+		"\t\t      ^\n"
+	if out := perr.Diagnostic(); out != exp {
+		t.Errorf("expected:\n%s\nfound:\n%s", exp, out)
+	}
+
+	perr = &ParseError{Line: 3, Err: ErrLineTooLong}
+	if exp, out := "3: line too long\n", perr.Diagnostic(); out != exp {
+		t.Errorf("expected %q but found %q", exp, out)
+	}
+}
+
+// TestParse_CollectErrors asserts that every invalid line is reported and
+// valid pairs are still returned.
+func TestParse_CollectErrors(t *testing.T) {
+	buf := "A=1\nx\nB=\"2\nC=3\n1D=4\nE='5'\n"
+
+	env, err := ParseWithOptions(bytes.NewBufferString(buf), WithCollectErrors())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatalf("expected envparse.ParseErrors but found %T", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors but found %d: %v", len(errs), err)
+	}
+	for i, exp := range []int{2, 3, 5} {
+		if errs[i].Line != exp {
+			t.Errorf("expected error %d on line %d but found %d", i, exp, errs[i].Line)
+		}
+	}
+	if !errors.Is(err, ErrUnmatchedDouble) || !errors.Is(err, ErrMissingSeparator) {
+		t.Errorf("expected errors to match sentinels: %v", err)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("expected first *ParseError but found %v", perr)
+	}
+
+	// Matching does not depend on errors following Unwrap() []error
+	if !errs.Is(ErrMissingSeparator) || errs.Is(ErrEmptyKey) {
+		t.Errorf("expected only present sentinels to match: %v", err)
+	}
+	var lerr *ParseError
+	if !errs.As(&lerr) || lerr.Line != 2 {
+		t.Errorf("expected first *ParseError but found %v", lerr)
+	}
+	if !errors.Is(fmt.Errorf("loading: %w", err), ErrUnmatchedDouble) {
+		t.Errorf("expected wrapped errors to match sentinels: %v", err)
+	}
+
+	expected := map[string]string{"A": "1", "C": "3", "E": "5"}
+	if len(env) != len(expected) {
+		t.Errorf("expected %d keys but found %d: %#v", len(expected), len(env), env)
+	}
+	for k, v := range expected {
+		if env[k] != v {
+			t.Errorf("expected %s=%q but found %q", k, v, env[k])
+		}
+	}
+
+	pairs, err := ParsePairsWithOptions(bytes.NewBufferString(buf), WithCollectErrors())
+	if errs, ok := err.(ParseErrors); !ok || len(errs) != 3 {
+		t.Errorf("expected 3 errors but found %v", err)
+	}
+	if len(pairs) != 3 {
+		t.Errorf("expected 3 pairs but found %v", pairs)
+	}
+
+	// Valid input returns no error
+	if _, err := ParseWithOptions(bytes.NewBufferString("A=1"), WithCollectErrors()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestParse_OnError asserts that OnError can skip or abort per error.
+func TestParse_OnError(t *testing.T) {
+	buf := "A=1\nx\nB=2\n1C=3\nD=4\n"
+
+	errAbort := errors.New("abort")
+	seen := []int{}
+	onError := func(perr *ParseError) error {
+		seen = append(seen, perr.Line)
+		if errors.Is(perr, ErrMissingSeparator) {
+			return nil
+		}
+		return errAbort
+	}
+
+	env, err := ParseWithOptions(bytes.NewBufferString(buf), WithOnError(onError))
+	if err != errAbort {
+		t.Fatalf("expected %v but found %v", errAbort, err)
+	}
+	if env != nil {
+		t.Errorf("unexpected env: %#v", env)
+	}
+	if len(seen) != 2 || seen[0] != 2 || seen[1] != 4 {
+		t.Errorf("expected errors on lines [2 4] but found %v", seen)
+	}
+
+	// Skipped errors are not returned unless collected
+	p := NewWithOptions(bytes.NewBufferString(buf), WithOnError(func(*ParseError) error { return nil }))
+	n := 0
+	for {
+		_, err := p.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n++
+	}
+	if n != 3 || len(p.Errors()) != 0 {
+		t.Errorf("expected 3 pairs and no errors but found %d and %v", n, p.Errors())
+	}
+}
+
+// TestParse_CollectErrors_Fatal asserts read errors are never skipped.
+func TestParse_CollectErrors_Fatal(t *testing.T) {
+	buf := "x\nA=123456789\nB=1\n"
+	_, err := ParseWithOptions(bytes.NewBufferString(buf), WithCollectErrors(), WithMaxLineSize(8))
+	perr, ok := err.(*ParseError)
+	if !ok || perr.Err != ErrLineTooLong || perr.Line != 2 {
+		t.Fatalf("expected %v on line 2 but found %v", ErrLineTooLong, err)
+	}
+}
+
+// TestParse_LineTooLong asserts that lines exceeding the maximum line size
+// return ErrLineTooLong on the correct line.
+func TestParse_LineTooLong(t *testing.T) {
+	long := strings.Repeat("x", bufio.MaxScanTokenSize)
+	cases := []struct {
+		name string
+		buf  string
+		opts []Option
+		n    int
+	}{
+		{"Default", "A=1\nB=2\nC=" + long + "\n", nil, 3},
+		{"MaxLineSize", "A=1\n\nB=1234567\n", []Option{WithMaxLineSize(8)}, 3},
+		{"MaxLineSizeCRLF", "A=123456\r\nB=1234567\r\n", []Option{WithMaxLineSize(8)}, 2},
+		{"MaxLineSizeEOF", "A=123456\nB=1234567", []Option{WithMaxLineSize(8)}, 2},
+		{"Multiline", "A='1\n234567890'\n", []Option{WithMaxLineSize(8), WithMultiline()}, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), c.opts...)
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T", err)
+			}
+			if perr.Line != c.n || perr.Err != ErrLineTooLong {
+				t.Errorf("expected %v on line %d but found %v", ErrLineTooLong, c.n, err)
+			}
+		})
+	}
+
+	// Lines exactly the maximum size are ok
+	env, err := ParseWithOptions(strings.NewReader("A=123456\r\nB=1\n"), WithMaxLineSize(8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env["A"] != "123456" {
+		t.Errorf("expected A=123456 but found %q", env["A"])
+	}
+
+	// Unbounded lines
+	huge := strings.Repeat("x", 4*bufio.MaxScanTokenSize)
+	env, err = ParseWithOptions(strings.NewReader("A="+huge+"\n"), WithMaxLineSize(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env["A"] != huge {
+		t.Errorf("expected %d byte value but found %d", len(huge), len(env["A"]))
+	}
+}
+
 func TestParseLine_OK(t *testing.T) {
 	cases := []struct {
 		name string
@@ -218,6 +535,8 @@ func TestParseLine_OK(t *testing.T) {
 		{"AllModes", `export FOO =  'single\n' \\normal\t "double\"\n " # comment`, "FOO", "single\\n \\\\normal\\t double\"\n "},
 		{"UnicodeLiteral", "U1=\U0001F525", "U1", "\U0001F525"},
 		{"UnicodeLiteralQuoted", "U2= ' \U0001F525 ' ", "U2", " \U0001F525 "},
+		{"UnicodeLiteralComment", "U1=caf\u00e9 # note", "U1", "caf\u00e9"},
+		{"UnicodeLiteralSpacesComment", "U1=\U0001F525  \U0001F525  # note", "U1", "\U0001F525  \U0001F525"},
 		{"EscapedUnicode1byte", `U3="\u2318"`, "U3", "\U00002318"},
 		{"EscapedUnicode2byte", `U3="\uD83D\uDE01"`, "U3", "\U0001F601"},
 		{"EscapedUnicodeCombined", `U4="\u2318\uD83D\uDE01"`, "U4", "\U00002318\U0001F601"},
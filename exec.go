@@ -0,0 +1,98 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ParseFiles parses each named file in order using a Parser configured with
+// the given options and returns their deduplicated key/value pairs. Keys in
+// later files override those in earlier files but keep their first position.
+//
+// The File of every ParseError is the name of the file it occurred in. If
+// WithCollectErrors is enabled the valid pairs of every file are returned
+// along with the ParseErrors of all of them.
+func ParseFiles(names []string, opts ...Option) ([]Pair, error) {
+	env := []Pair{}
+	index := make(map[string]int)
+	errs := ParseErrors{}
+	for _, name := range names {
+		pairs, err := parseFile(name, opts)
+		if perrs, ok := err.(ParseErrors); ok {
+			errs = append(errs, perrs...)
+		} else if err != nil {
+			return nil, err
+		}
+		for _, kv := range pairs {
+			if i, ok := index[kv.Key]; ok {
+				env[i] = kv
+				continue
+			}
+			index[kv.Key] = len(env)
+			env = append(env, kv)
+		}
+	}
+	if len(errs) > 0 {
+		return env, errs
+	}
+	return env, nil
+}
+
+// parseFile parses the named file into a slice of deduplicated key/value
+// pairs.
+func parseFile(name string, opts []Option) ([]Pair, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	opts = append(opts[:len(opts):len(opts)], WithSource(name))
+	return ParsePairsWithOptions(f, opts...)
+}
+
+// Environ returns a copy of env, a list of KEY=value strings as returned by
+// os.Environ, with each pair applied. Pairs replace any existing definition
+// of their key and are otherwise appended.
+func Environ(env []string, pairs []Pair) []string {
+	out := make([]string, len(env), len(env)+len(pairs))
+	copy(out, env)
+
+	index := make(map[string]int, len(out))
+	for i, kv := range out {
+		if eq := strings.IndexByte(kv, '='); eq >= 0 {
+			index[kv[:eq]] = i
+		}
+	}
+	for _, p := range pairs {
+		kv := p.Key + "=" + p.Val
+		if i, ok := index[p.Key]; ok {
+			out[i] = kv
+			continue
+		}
+		index[p.Key] = len(out)
+		out = append(out, kv)
+	}
+	return out
+}
+
+// Command returns an exec.Cmd which runs the named program with the given
+// arguments in the environment of the current process with the pairs from
+// files applied in order. Files are parsed with ParseFiles using opts.
+//
+// To run without the inherited environment use ParseFiles and set the Env
+// field of the command to Environ(nil, pairs) instead.
+func Command(files []string, opts []Option, name string, arg ...string) (*exec.Cmd, error) {
+	pairs, err := ParseFiles(files, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(name, arg...)
+	cmd.Env = Environ(os.Environ(), pairs)
+	return cmd, nil
+}
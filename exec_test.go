@@ -0,0 +1,107 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes each file to a temporary directory and returns their
+// paths along with the directory to remove.
+func writeFiles(t *testing.T, files ...string) ([]string, string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "envparse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = filepath.Join(dir, string(rune('a'+i))+".env")
+		if err := ioutil.WriteFile(names[i], []byte(f), 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return names, dir
+}
+
+func TestParseFiles(t *testing.T) {
+	names, dir := writeFiles(t, "A=1\nB=1\n", "C=2\nA=2\n")
+	defer os.RemoveAll(dir)
+	pairs, err := ParseFiles(names)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Pair{{"A", "2"}, {"B", "1"}, {"C", "2"}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("expected %v but found %v", expected, pairs)
+	}
+}
+
+func TestParseFiles_Err(t *testing.T) {
+	names, dir := writeFiles(t, "A=1\n", "B\n")
+	defer os.RemoveAll(dir)
+	_, err := ParseFiles(names)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.HasPrefix(err.Error(), names[1]+": ") || !errors.Is(err, ErrMissingSeparator) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = ParseFiles([]string{names[0] + ".missing"})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not exist error but found %v", err)
+	}
+}
+
+func TestParseFiles_CollectErrors(t *testing.T) {
+	names, dir := writeFiles(t, "A=1\nx\nB=1\n", "C=2\ny\nA=2\n")
+	defer os.RemoveAll(dir)
+	pairs, err := ParseFiles(names, WithCollectErrors())
+
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 2 || errs[0].File != names[0] || errs[1].File != names[1] {
+		t.Fatalf("expected an error in each file but found %v", err)
+	}
+	expected := []Pair{{"A", "2"}, {"B", "1"}, {"C", "2"}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("expected %v but found %v", expected, pairs)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	env := []string{"A=1", "B=1"}
+	out := Environ(env, []Pair{{"B", "2"}, {"C", "2=3"}})
+
+	expected := []string{"A=1", "B=2", "C=2=3"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %v but found %v", expected, out)
+	}
+	if env[1] != "B=1" {
+		t.Errorf("expected env to be unmodified but found %v", env)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	names, dir := writeFiles(t, "ENVPARSE_TEST=1\n")
+	defer os.RemoveAll(dir)
+	cmd, err := Command(names, nil, "true", "x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cmd.Args, []string{"true", "x"}) {
+		t.Errorf("unexpected args: %v", cmd.Args)
+	}
+	if last := cmd.Env[len(cmd.Env)-1]; last != "ENVPARSE_TEST=1" {
+		t.Errorf("expected ENVPARSE_TEST=1 but found %s", last)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"io/fs"
+	"os"
+)
+
+// ParseFile parses environment variables from the named file into a map
+// using a Parser configured with the given options. The File of every
+// ParseError is the name of the file.
+func ParseFile(name string, opts ...Option) (map[string]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	opts = append(opts[:len(opts):len(opts)], WithSource(name))
+	return ParseWithOptions(f, opts...)
+}
+
+// ParseFS parses environment variables from the named file in fsys, such as
+// an embed.FS, into a map using a Parser configured with the given options.
+// The File of every ParseError is the name of the file.
+func ParseFS(fsys fs.FS, name string, opts ...Option) (map[string]string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	opts = append(opts[:len(opts):len(opts)], WithSource(name))
+	return ParseWithOptions(f, opts...)
+}
+
+// ParseFSGlob parses every file in fsys matching pattern, as defined by
+// fs.Glob, in lexical order and merges them into a map. Keys in later files
+// take precedence over earlier ones. An empty map is returned if no files
+// match. If WithCollectErrors is enabled the valid pairs of every file are
+// returned along with the ParseErrors of all of them.
+func ParseFSGlob(fsys fs.FS, pattern string, opts ...Option) (map[string]string, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	errs := ParseErrors{}
+	for _, name := range names {
+		found, err := ParseFS(fsys, name, opts...)
+		if perrs, ok := err.(ParseErrors); ok {
+			errs = append(errs, perrs...)
+		} else if err != nil {
+			return nil, err
+		}
+		for k, v := range found {
+			env[k] = v
+		}
+	}
+	if len(errs) > 0 {
+		return env, errs
+	}
+	return env, nil
+}
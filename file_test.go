@@ -0,0 +1,104 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseFile(t *testing.T) {
+	names, dir := writeFiles(t, "A=1\n", "A=1\nB\n")
+	defer os.RemoveAll(dir)
+
+	env, err := ParseFile(names[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(env, map[string]string{"A": "1"}) {
+		t.Errorf("unexpected env: %v", env)
+	}
+
+	_, err = ParseFile(names[1])
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *envparse.ParseError but found %T", err)
+	}
+	if perr.File != names[1] || perr.Line != 2 {
+		t.Errorf("unexpected error: %#v", perr)
+	}
+	if exp := names[1] + ": error on line 2: missing ="; err.Error() != exp {
+		t.Errorf("expected %q but found %q", exp, err.Error())
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/.env": {Data: []byte("A=1\n")},
+		"config/bad":  {Data: []byte("A=\"1\n")},
+	}
+
+	env, err := ParseFS(fsys, "config/.env")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(env, map[string]string{"A": "1"}) {
+		t.Errorf("unexpected env: %v", env)
+	}
+
+	_, err = ParseFS(fsys, "config/bad", WithCollectErrors())
+	var errs ParseErrors
+	if !errors.As(err, &errs) || errs[0].File != "config/bad" {
+		t.Errorf("expected a ParseError for config/bad but found %v", err)
+	}
+	if exp := "config/bad:1:3: unmatched \"\n\tA=\"1\n\t  ^\n"; errs[0].Diagnostic() != exp {
+		t.Errorf("expected %q but found %q", exp, errs[0].Diagnostic())
+	}
+
+	if _, err := ParseFS(fsys, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not exist error but found %v", err)
+	}
+}
+
+func TestParseFSGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"env/10-base.env":     {Data: []byte("A=base\nB=base\n")},
+		"env/20-override.env": {Data: []byte("B=override\n")},
+		"env/README":          {Data: []byte("not an env file\n")},
+	}
+
+	env, err := ParseFSGlob(fsys, "env/*.env")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := map[string]string{"A": "base", "B": "override"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %v but found %v", expected, env)
+	}
+
+	env, err = ParseFSGlob(fsys, "none/*.env")
+	if err != nil || len(env) != 0 {
+		t.Errorf("expected an empty map but found %v, %v", env, err)
+	}
+
+	if _, err := ParseFSGlob(fsys, "env/*"); err == nil {
+		t.Errorf("expected an error parsing README")
+	}
+	if _, err := ParseFSGlob(fsys, "["); err == nil {
+		t.Errorf("expected an error for a bad pattern")
+	}
+
+	// Valid pairs of every file are kept when collecting errors
+	env, err = ParseFSGlob(fsys, "env/*", WithCollectErrors())
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 1 || errs[0].File != "env/README" {
+		t.Errorf("expected an error in env/README but found %v", err)
+	}
+	if expected := map[string]string{"A": "base", "B": "override"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %v but found %v", expected, env)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExportPrefix determines how Format writes export prefixes.
+type ExportPrefix uint8
+
+const (
+	// ExportKeep writes an export prefix only for keys which had one.
+	ExportKeep ExportPrefix = iota
+
+	// ExportAlways writes an export prefix for every key.
+	ExportAlways
+
+	// ExportNever removes every export prefix.
+	ExportNever
+)
+
+// FormatOptions configure Format. The zero value keeps export prefixes and
+// writes inline comments one space after their value.
+type FormatOptions struct {
+	Export ExportPrefix
+
+	// AlignComments aligns the inline comments of consecutive pairs.
+	AlignComments bool
+}
+
+// fmtLine is a formatted line without its inline comment.
+type fmtLine struct {
+	text    string
+	comment string
+	isPair  bool
+}
+
+// Format reads environment variables from r and writes them to w in a
+// canonical form which Parse reads back unchanged:
+//
+//   - Pairs are written as KEY=value with no whitespace around the = and the
+//     minimal quoting used by Encoder.
+//   - Inline comments are written as " # comment" after the value and empty
+//     inline comments are removed.
+//   - Comment lines and blank lines are kept with leading and trailing
+//     whitespace removed. Consecutive blank lines are collapsed to one, and
+//     blank lines at the start and end of the input are removed.
+//   - Every line ends with the line ending of the first line of the input.
+//
+// Duplicate keys are kept. Nothing is written if the input cannot be parsed
+// and a ParseError is returned.
+func Format(r io.Reader, w io.Writer, opts FormatOptions) error {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return parseError(0, err)
+	}
+
+	nl := lf
+	if i := bytes.IndexByte(buf, '\n'); i > 0 && buf[i-1] == '\r' {
+		nl = crlf
+	}
+
+	lines := []fmtLine{}
+	blank := false
+	for n, off := 1, 0; len(buf) > 0; n++ {
+		raw := buf
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			raw = buf[:i+1]
+		}
+		buf = buf[len(raw):]
+
+		ln := bytes.TrimSuffix(bytes.TrimSuffix(raw, lf), []byte{'\r'})
+		info := lineInfo{}
+		k, v, err := parseLineWith(ln, &defaultOptions, nil, &info)
+		if err != nil {
+			return lineParseError(n, off, ln, err)
+		}
+		off += len(raw)
+
+		trimmed := bytes.TrimSpace(ln)
+		switch {
+		case len(trimmed) == 0:
+			blank = len(lines) > 0
+			continue
+		case blank:
+			lines = append(lines, fmtLine{})
+			blank = false
+		}
+
+		if len(k) == 0 {
+			lines = append(lines, fmtLine{text: string(trimmed)})
+			continue
+		}
+
+		text := []byte{}
+		if opts.Export == ExportAlways || (opts.Export == ExportKeep && info.export) {
+			text = append(text, exportPrefix...)
+		}
+		text = appendPair(text, Pair{Key: string(k), Val: string(v)})
+		lines = append(lines, fmtLine{
+			text:    string(text[:len(text)-1]),
+			comment: string(info.comment),
+			isPair:  true,
+		})
+	}
+
+	if opts.AlignComments {
+		alignComments(lines)
+	}
+
+	out := []byte{}
+	for _, l := range lines {
+		out = append(out, l.text...)
+		if l.comment != "" {
+			out = append(out, " # "...)
+			out = append(out, l.comment...)
+		}
+		out = append(out, nl...)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// alignComments pads the text of consecutive pairs with inline comments so
+// their comments start in the same column.
+func alignComments(lines []fmtLine) {
+	for start := 0; start < len(lines); {
+		end := start
+		width := 0
+		for end < len(lines) && lines[end].isPair {
+			if n := utf8.RuneCountInString(lines[end].text); lines[end].comment != "" && n > width {
+				width = n
+			}
+			end++
+		}
+		for i := start; i < end; i++ {
+			if l := &lines[i]; l.comment != "" {
+				l.text += strings.Repeat(" ", width-utf8.RuneCountInString(l.text))
+			}
+		}
+		if end == start {
+			end++
+		}
+		start = end
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	in := "\n\n  # header  \n" +
+		"export A = 1   # one\n" +
+		"LONGER_KEY=\"no quotes needed\"#two\n" +
+		"\n\n\n" +
+		"B = 'it''s'\n" +
+		"C=\"\" #\n" +
+		"D=\"a\\nb\"\n" +
+		"\n"
+
+	cases := []struct {
+		name string
+		opts FormatOptions
+		out  string
+	}{
+		{"Default", FormatOptions{}, "# header\n" +
+			"export A=1 # one\n" +
+			"LONGER_KEY=no quotes needed # two\n" +
+			"\n" +
+			"B=its\n" +
+			"C=\n" +
+			"D=\"a\\nb\"\n"},
+		{"Align", FormatOptions{AlignComments: true}, "# header\n" +
+			"export A=1                  # one\n" +
+			"LONGER_KEY=no quotes needed # two\n" +
+			"\n" +
+			"B=its\n" +
+			"C=\n" +
+			"D=\"a\\nb\"\n"},
+		{"ExportAlways", FormatOptions{Export: ExportAlways}, "# header\n" +
+			"export A=1 # one\n" +
+			"export LONGER_KEY=no quotes needed # two\n" +
+			"\n" +
+			"export B=its\n" +
+			"export C=\n" +
+			"export D=\"a\\nb\"\n"},
+		{"ExportNever", FormatOptions{Export: ExportNever}, "# header\n" +
+			"A=1 # one\n" +
+			"LONGER_KEY=no quotes needed # two\n" +
+			"\n" +
+			"B=its\n" +
+			"C=\n" +
+			"D=\"a\\nb\"\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			if err := Format(strings.NewReader(in), &out, c.opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != c.out {
+				t.Errorf("expected:\n%s\nbut found:\n%s", c.out, out.String())
+			}
+
+			// Formatting must not change the parsed pairs
+			before, err := ParsePairs(strings.NewReader(in))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			after, err := ParsePairs(&out)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("expected %v but found %v", before, after)
+			}
+		})
+	}
+}
+
+func TestFormat_Idempotent(t *testing.T) {
+	in := "# c\r\nA = 'x y' # z\r\n\r\n\r\nB=\"\\u00e9\"\r\nGREETING=caf\u00e9 # note\r\nC=\"\U0001F525\"#x\r\n"
+	first := bytes.Buffer{}
+	if err := Format(strings.NewReader(in), &first, FormatOptions{AlignComments: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := "# c\r\nA=x y # z\r\n\r\nB=\u00e9\r\nGREETING=caf\u00e9 # note\r\nC=\U0001F525           # x\r\n"; first.String() != exp {
+		t.Errorf("expected %q but found %q", exp, first.String())
+	}
+
+	second := bytes.Buffer{}
+	if err := Format(bytes.NewReader(first.Bytes()), &second, FormatOptions{AlignComments: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("expected %q but found %q", first.String(), second.String())
+	}
+}
+
+func TestFormat_Err(t *testing.T) {
+	out := bytes.Buffer{}
+	err := Format(strings.NewReader("A=1\nB\n"), &out, FormatOptions{})
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *envparse.ParseError but found %T", err)
+	}
+	if perr.Line != 2 || !errors.Is(err, ErrMissingSeparator) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be written but found %q", out.String())
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+)
+
+var (
+	ErrUndefinedVariable = fmt.Errorf("undefined variable")
+	ErrInvalidVariable   = fmt.Errorf("invalid variable reference")
+)
+
+// expander expands variable references using the keys defined so far and an
+// optional lookup function.
+type expander struct {
+	vars   map[string]string
+	lookup func(string) (string, bool)
+	strict bool
+}
+
+// get the value of a variable. Keys defined in the input take precedence over
+// the lookup function.
+func (e *expander) get(name string) (string, bool) {
+	if v, ok := e.vars[name]; ok {
+		return v, true
+	}
+	if e.lookup != nil {
+		return e.lookup(name)
+	}
+	return "", false
+}
+
+// expand the variable reference at the start of s, which must begin with $.
+// Returns the expanded value and the number of bytes of s consumed.
+//
+// A $ not followed by a valid reference is returned as-is.
+func (e *expander) expand(s []byte) ([]byte, int, error) {
+	if len(s) < 2 {
+		return s[:1], 1, nil
+	}
+
+	if s[1] != '{' {
+		// $VAR form
+		n := 1
+		for n < len(s) && isNameChar(s[n], n == 1) {
+			n++
+		}
+		if n == 1 {
+			// Not a reference
+			return s[:1], 1, nil
+		}
+		v, err := e.resolve(string(s[1:n]))
+		return []byte(v), n, err
+	}
+
+	// ${VAR...} form; find the matching brace
+	end := -1
+	depth := 0
+	for i := 2; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}' && depth > 0:
+			depth--
+		case s[i] == '}':
+			end = i
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if end < 0 {
+		return nil, 0, fmt.Errorf("%w: unterminated %q", ErrInvalidVariable, string(s))
+	}
+
+	ref := s[2:end]
+	n := 0
+	for n < len(ref) && isKeyChar(ref[n], n == 0) {
+		n++
+	}
+	name := string(ref[:n])
+	if name == "" {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidVariable, string(s[:end+1]))
+	}
+
+	switch op := ref[n:]; {
+	case len(op) == 0:
+		v, err := e.resolve(name)
+		return []byte(v), end + 1, err
+	case bytes.HasPrefix(op, []byte(":-")):
+		if v, ok := e.get(name); ok && v != "" {
+			return []byte(v), end + 1, nil
+		}
+		v, err := e.expandAll(op[2:])
+		return v, end + 1, err
+	case bytes.HasPrefix(op, []byte(":?")):
+		if v, ok := e.get(name); ok && v != "" {
+			return []byte(v), end + 1, nil
+		}
+		msg, err := e.expandAll(op[2:])
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(msg) == 0 {
+			return nil, 0, fmt.Errorf("%w: %s", ErrUndefinedVariable, name)
+		}
+		return nil, 0, fmt.Errorf("%w: %s: %s", ErrUndefinedVariable, name, msg)
+	default:
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidVariable, string(s[:end+1]))
+	}
+}
+
+// resolve the value of a variable, returning an error if it is undefined and
+// strict mode is enabled.
+func (e *expander) resolve(name string) (string, error) {
+	v, ok := e.get(name)
+	if !ok && e.strict {
+		return "", fmt.Errorf("%w: %s", ErrUndefinedVariable, name)
+	}
+	return v, nil
+}
+
+// expandAll expands every variable reference in s.
+func (e *expander) expandAll(s []byte) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		if s[i] != '$' {
+			out = append(out, s[i])
+			i++
+			continue
+		}
+		v, n, err := e.expand(s[i:])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v...)
+		i += n
+	}
+	return out, nil
+}
+
+// isNameChar returns true if c may be used in a $VAR style reference which,
+// like the shell, does not include the . and / characters allowed in keys.
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_':
+	case c >= 'A' && c <= 'Z':
+	case c >= 'a' && c <= 'z':
+	case c >= '0' && c <= '9' && !first:
+	default:
+		return false
+	}
+	return true
+}
+
+// isKeyChar returns true if c may be used in a ${VAR} style reference.
+func isKeyChar(c byte, first bool) bool {
+	return isNameChar(c, first) || (!first && (c == '.' || c == '/'))
+}
+
+// growValue ensures buf has room for n more bytes after its first used bytes.
+func growValue(buf []byte, used, n int) []byte {
+	if len(buf)-used >= n {
+		return buf
+	}
+	nbuf := make([]byte, used+n)
+	copy(nbuf, buf[:used])
+	return nbuf
+}
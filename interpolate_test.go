@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testLookup(env map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+}
+
+func TestParse_Interpolation(t *testing.T) {
+	buf := `
+HOST=localhost
+PORT=8080
+URL=http://$HOST:${PORT}/
+QUOTED="${HOST} \$HOST"
+SINGLE='${HOST}'
+MIXED=$HOST'$HOST'"$HOST"
+DEFAULT=${MISSING:-fallback}
+EMPTY=
+EMPTY_DEFAULT=${EMPTY:-${HOST}}
+NESTED=${MISSING:-${ALSO_MISSING:-deep}}
+PROCESS=${FROM_PROCESS}
+OVERRIDE=${USER}
+DOTTED.KEY=dot
+DOT=${DOTTED.KEY} $DOTTED.KEY
+LATER=${DEFINED_LATER}
+DEFINED_LATER=x
+SELF=a
+SELF=${SELF}b
+DOLLAR=$ 5 $1 $
+COMMENT=$HOST # $PORT
+`
+	lookup := testLookup(map[string]string{
+		"FROM_PROCESS": "proc",
+		"USER":         "process-user",
+	})
+	env, err := ParseWithOptions(strings.NewReader(buf+"USER=file-user\nOVERRIDE2=$USER\n"), WithInterpolation(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"URL":           "http://localhost:8080/",
+		"QUOTED":        "localhost $HOST",
+		"SINGLE":        "${HOST}",
+		"MIXED":         "localhost$HOSTlocalhost",
+		"DEFAULT":       "fallback",
+		"EMPTY_DEFAULT": "localhost",
+		"NESTED":        "deep",
+		"PROCESS":       "proc",
+		"OVERRIDE":      "process-user",
+		"OVERRIDE2":     "file-user",
+		"DOT":           "dot .KEY",
+		"LATER":         "",
+		"SELF":          "ab",
+		"DOLLAR":        "$ 5 $1 $",
+		"COMMENT":       "localhost",
+	}
+	for k, v := range expected {
+		if env[k] != v {
+			t.Errorf("expected %s=%q but found %q", k, v, env[k])
+		}
+	}
+}
+
+func TestParse_Interpolation_Disabled(t *testing.T) {
+	env, err := Parse(strings.NewReader("A=1\nB=${A}\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := "${A}"; env["B"] != exp {
+		t.Errorf("expected %q but found %q", exp, env["B"])
+	}
+
+	if _, err := Parse(strings.NewReader(`B="\$A"`)); err == nil {
+		t.Errorf("expected \\$ to be an invalid escape without interpolation")
+	}
+}
+
+func TestParse_Interpolation_Err(t *testing.T) {
+	cases := []struct {
+		name   string
+		buf    string
+		strict bool
+		n      int
+		err    error
+		msg    string
+	}{
+		{"Unterminated", "A=1\nB=${A\n", false, 2, ErrInvalidVariable, ""},
+		{"EmptyName", "A=${}", false, 1, ErrInvalidVariable, ""},
+		{"BadOperator", "A=${B:=x}", false, 1, ErrInvalidVariable, ""},
+		{"Required", "\nA=${B:?B must be set}", false, 2, ErrUndefinedVariable, "B: B must be set"},
+		{"RequiredEmpty", "B=\nA=${B:?}", false, 2, ErrUndefinedVariable, "B"},
+		{"Strict", "A=1\n\nB=$A$C", true, 3, ErrUndefinedVariable, "C"},
+		{"StrictBraces", `B="${C}"`, true, 1, ErrUndefinedVariable, "C"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := []Option{WithInterpolation(nil)}
+			if c.strict {
+				opts = append(opts, WithStrictInterpolation())
+			}
+
+			env, err := ParseWithOptions(strings.NewReader(c.buf), opts...)
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T", err)
+			}
+			if perr.Line != c.n {
+				t.Errorf("expected error on line %d but found %d", c.n, perr.Line)
+			}
+			if !errors.Is(err, c.err) {
+				t.Errorf("expected %v but found %v", c.err, err)
+			}
+			if c.msg != "" && !strings.HasSuffix(err.Error(), c.msg) {
+				t.Errorf("expected error to end with %q but found %v", c.msg, err)
+			}
+		})
+	}
+}
+
+func TestParse_Interpolation_Strict(t *testing.T) {
+	// Single quotes and escaped references are never expanded
+	buf := `A='$MISSING'` + "\n" + `B="\${MISSING}"`
+	env, err := ParseWithOptions(strings.NewReader(buf), WithInterpolation(nil), WithStrictInterpolation())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env["A"] != "$MISSING" || env["B"] != "${MISSING}" {
+		t.Errorf("unexpected values: %#v", env)
+	}
+}
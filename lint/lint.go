@@ -0,0 +1,317 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package lint reports likely mistakes in environment variable files which
+// the envparse package would otherwise accept, and fixes those which can be
+// corrected mechanically.
+//
+// Each issue may be suppressed with a comment naming its rules, either inline
+// on the line or on the line before it:
+//
+//	# envparse:ignore lowercase-key
+//	database_url=postgres://localhost
+//	API_TOKEN=abc123 # envparse:ignore secret
+//
+// A comment of just envparse:ignore suppresses every rule.
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// Severity is how serious an Issue is.
+type Severity uint8
+
+const (
+	// Info is for stylistic issues.
+	Info Severity = iota
+
+	// Warning is for issues which are likely mistakes.
+	Warning
+
+	// Error is for issues which should never be committed.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", s)
+	}
+}
+
+// ParseSeverity returns the Severity named by s.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{Info, Warning, Error} {
+		if s == sev.String() {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", s)
+}
+
+// Issue is a problem found by a rule.
+type Issue struct {
+	Rule     string
+	Severity Severity
+
+	// Line is the line the issue was found on and Column its 1-based byte
+	// column, or 0 if the issue applies to the whole line.
+	Line   int
+	Column int
+
+	// Key is set if the issue concerns a pair.
+	Key     string
+	Message string
+
+	// Fixable is true if Fix corrects the issue.
+	Fixable bool
+
+	fix *edit
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s [%s]", i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// edit replaces the n lines starting at line with the result of fn.
+type edit struct {
+	line int
+	n    int
+	fn   func(lines []string) []string
+}
+
+// Config selects the rules to run and how the input is parsed. The zero
+// value runs every rule with its default severity on input in the default
+// envparse syntax.
+type Config struct {
+	// Disable lists the names of rules which are not run.
+	Disable []string
+
+	// Severity overrides the default severity of rules by name.
+	Severity map[string]Severity
+
+	// Options are passed to the envparse Parser. Duplicate keys are checked
+	// by the duplicate-key rule so WithDuplicates should not be used.
+	Options []envparse.Option
+}
+
+// enabled returns true if the rule should be run.
+func (c *Config) enabled(r *Rule) bool {
+	for _, name := range c.Disable {
+		if name == r.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// severity returns the configured severity of the rule.
+func (c *Config) severity(r *Rule) Severity {
+	if s, ok := c.Severity[r.Name]; ok {
+		return s
+	}
+	return r.Severity
+}
+
+// check returns an error if the configuration refers to unknown rules.
+func (c *Config) check() error {
+	for _, name := range c.Disable {
+		if findRule(name) == nil {
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	for name := range c.Severity {
+		if findRule(name) == nil {
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	return nil
+}
+
+// file is the input being linted.
+type file struct {
+	// lines of the input without line endings
+	lines   []string
+	entries []envparse.Entry
+	opts    []envparse.Option
+}
+
+// Lint returns every issue found in r sorted by line. If r cannot be parsed
+// the error from the Parser is returned, wrapping a ParseErrors with every
+// line which could not be parsed.
+func Lint(r io.Reader, cfg Config) ([]Issue, error) {
+	_, issues, err := lint(r, cfg)
+	return issues, err
+}
+
+// Fix writes r to w with every fixable issue corrected and returns the issues
+// which remain. Lines without issues are written unchanged.
+func Fix(r io.Reader, w io.Writer, cfg Config) ([]Issue, error) {
+	f, issues, err := lint(r, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply edits from the last line so earlier line numbers stay valid
+	edits := map[int][]*edit{}
+	remaining := []Issue{}
+	for _, i := range issues {
+		if i.fix == nil {
+			remaining = append(remaining, i)
+			continue
+		}
+		edits[i.fix.line] = append(edits[i.fix.line], i.fix)
+	}
+
+	lines := f.lines
+	for n := len(lines); n >= 1; n-- {
+		for _, e := range edits[n] {
+			end := e.line - 1 + e.n
+			if end > len(lines) {
+				end = len(lines)
+			}
+			fixed := e.fn(append([]string{}, lines[e.line-1:end]...))
+			lines = append(lines[:e.line-1], append(fixed, lines[end:]...)...)
+		}
+	}
+
+	buf := []byte{}
+	for i, ln := range lines {
+		if i > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, ln...)
+	}
+	if _, err := w.Write(buf); err != nil {
+		return nil, err
+	}
+
+	// Later edits may have moved lines, so the remaining issues are found
+	// again in the fixed input
+	if len(remaining) == 0 {
+		return remaining, nil
+	}
+	return Lint(bytes.NewReader(buf), cfg)
+}
+
+// lint parses r and runs every enabled rule.
+func lint(r io.Reader, cfg Config) (*file, []Issue, error) {
+	if err := cfg.check(); err != nil {
+		return nil, nil, err
+	}
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := &file{
+		lines: strings.Split(string(buf), "\n"),
+		opts:  append(cfg.Options[:len(cfg.Options):len(cfg.Options)], envparse.WithEmptyValues()),
+	}
+	opts := append(f.opts[:len(f.opts):len(f.opts)], envparse.WithCollectErrors())
+	p := envparse.NewWithOptions(bytes.NewReader(buf), opts...)
+	for {
+		e, err := p.NextEntry()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		f.entries = append(f.entries, e)
+	}
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	ignored := f.ignored()
+	issues := []Issue{}
+	for _, rule := range rules {
+		if !cfg.enabled(rule) {
+			continue
+		}
+		for _, i := range rule.check(f) {
+			if ignored[i.Line][rule.Name] || ignored[i.Line][""] {
+				continue
+			}
+			i.Rule = rule.Name
+			i.Severity = cfg.severity(rule)
+			i.Fixable = i.fix != nil
+			issues = append(issues, i)
+		}
+	}
+
+	sortIssues(issues)
+	return f, issues, nil
+}
+
+// sortIssues sorts issues by line and column keeping the rule order of
+// issues at the same position.
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+}
+
+// ignorePrefix starts a comment suppressing issues.
+const ignorePrefix = "envparse:ignore"
+
+// ignored returns the rules suppressed on each line. An empty rule name
+// suppresses every rule.
+func (f *file) ignored() map[int]map[string]bool {
+	ignored := map[int]map[string]bool{}
+	add := func(line int, comment string) {
+		comment = strings.TrimSpace(comment)
+		if !strings.HasPrefix(comment, ignorePrefix) {
+			return
+		}
+		rest := comment[len(ignorePrefix):]
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			return
+		}
+
+		if ignored[line] == nil {
+			ignored[line] = map[string]bool{}
+		}
+		names := strings.FieldsFunc(rest, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(names) == 0 {
+			ignored[line][""] = true
+		}
+		for _, name := range names {
+			ignored[line][name] = true
+		}
+	}
+
+	for i, ln := range f.lines {
+		if trimmed := strings.TrimSpace(ln); strings.HasPrefix(trimmed, "#") {
+			add(i+2, trimmed[1:])
+		}
+	}
+	for _, e := range f.entries {
+		add(e.Line, e.Comment)
+	}
+	return ignored
+}
+
+// span returns the number of lines e was parsed from.
+func span(e envparse.Entry) int {
+	return bytes.Count(e.Raw, []byte{'\n'}) + 1
+}
@@ -0,0 +1,144 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		issues []string
+	}{
+		{"Clean", "A=1\nB='x y' # comment\n", nil},
+		{"LowercaseKey", "a=1\n", []string{"1:1: warning: key a is not uppercase [lowercase-key]"}},
+		{"LowercaseExportKey", "export port=1\n  export ex=1\n", []string{
+			"1:8: warning: key port is not uppercase [lowercase-key]",
+			"2:10: warning: key ex is not uppercase [lowercase-key]",
+		}},
+		{"KeyCharacters", "A.B=1\nC/D=2\n", []string{
+			`1:2: warning: key A.B contains '.' which shells do not allow [key-characters]`,
+			`2:2: warning: key C/D contains '/' which shells do not allow [key-characters]`,
+		}},
+		{"TrailingWhitespace", "# x \nA=1\t\n \n", []string{
+			"1:4: info: trailing whitespace [trailing-whitespace]",
+			"2:4: info: trailing whitespace [trailing-whitespace]",
+			"3:1: info: trailing whitespace [trailing-whitespace]",
+		}},
+		{"SpaceBeforeComment", "A=foo  # x\nB=\"foo\"  # x\nC=foo # x\nD=a b   # x\n", []string{
+			"1:6: info: whitespace before the comment is not part of the value [space-before-comment]",
+			"4:6: info: whitespace before the comment is not part of the value [space-before-comment]",
+		}},
+		{"DuplicateKey", "A=1\nB=1\nA=2\n", []string{
+			"3:1: warning: duplicate key A (previously defined on line 1) [duplicate-key]",
+		}},
+		{"InconsistentExport", "export A=1\nB=2\n", []string{
+			"2:1: warning: key B has no export prefix but line 1 does [inconsistent-export]",
+		}},
+		{"Secret", "API_TOKEN=abc123\nDB_PASSWORD=\nSECRET_KEY=changeme\nAWS_ACCESS_KEY=${KEY}\nPASSWORD=xxxx\n", []string{
+			"1:1: error: key API_TOKEN looks like a secret and has a value [secret]",
+		}},
+		{"Ignore", "# envparse:ignore lowercase-key\na=1\nb=1 # envparse:ignore\nc.d=1 # envparse:ignore key-characters\n", []string{
+			"4:1: warning: key c.d is not uppercase [lowercase-key]",
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			issues, err := Lint(strings.NewReader(c.in), Config{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(issues) != len(c.issues) {
+				t.Fatalf("expected %d issues but found %d: %v", len(c.issues), len(issues), issues)
+			}
+			for i, exp := range c.issues {
+				if found := issues[i].String(); found != exp {
+					t.Errorf("expected %q but found %q", exp, found)
+				}
+			}
+		})
+	}
+}
+
+func TestLint_Config(t *testing.T) {
+	in := "a=1\nA.B=1\n"
+	issues, err := Lint(strings.NewReader(in), Config{
+		Disable:  []string{"key-characters"},
+		Severity: map[string]Severity{"lowercase-key": Error},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Rule != "lowercase-key" || issues[0].Severity != Error {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+
+	if _, err := Lint(strings.NewReader(in), Config{Disable: []string{"nope"}}); err == nil {
+		t.Errorf("expected an error for an unknown rule")
+	}
+}
+
+func TestLint_Dialect(t *testing.T) {
+	// Docker keeps trailing whitespace so it is not reported
+	issues, err := Lint(strings.NewReader("A=1 \n"), Config{
+		Options: []envparse.Option{envparse.WithDialect(envparse.DialectDocker)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+}
+
+func TestLint_ParseError(t *testing.T) {
+	_, err := Lint(strings.NewReader("A\nB=1\nC\n"), Config{})
+	var errs envparse.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 ParseErrors but found %v", err)
+	}
+}
+
+func TestFix(t *testing.T) {
+	in := "export A=1\n" +
+		"B=foo   # x  \r\n" +
+		"A=2\n" +
+		"export lower=1 \n" +
+		"C=2 # envparse:ignore inconsistent-export\n"
+	expected := "export B=foo # x\r\n" +
+		"export A=2\n" +
+		"export lower=1\n" +
+		"C=2 # envparse:ignore inconsistent-export\n"
+
+	out := bytes.Buffer{}
+	issues, err := Fix(strings.NewReader(in), &out, Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%q\nbut found:\n%q", expected, out.String())
+	}
+	if len(issues) != 1 || issues[0].String() != "3:8: warning: key lower is not uppercase [lowercase-key]" {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+
+	// Fixed output has no fixable issues
+	issues, err = Lint(strings.NewReader(out.String()), Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, i := range issues {
+		if i.Fixable {
+			t.Errorf("unexpected fixable issue: %v", i)
+		}
+	}
+}
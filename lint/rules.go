@@ -0,0 +1,403 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	envparse "github.com/hashicorp/go-envparse"
+)
+
+// Rule is a check run by Lint.
+type Rule struct {
+	Name        string
+	Description string
+
+	// Severity is the default severity of issues found by the rule.
+	Severity Severity
+
+	// Fixable is true if Fix can correct the issues found by the rule.
+	Fixable bool
+
+	check func(f *file) []Issue
+}
+
+// rules in the order they are run.
+var rules = []*Rule{
+	{
+		Name:        "lowercase-key",
+		Description: "keys should be uppercase",
+		Severity:    Warning,
+		check:       checkLowercaseKey,
+	},
+	{
+		Name:        "key-characters",
+		Description: "keys should be valid shell variable names without . / or -",
+		Severity:    Warning,
+		check:       checkKeyCharacters,
+	},
+	{
+		Name:        "trailing-whitespace",
+		Description: "lines should not end with whitespace",
+		Severity:    Info,
+		Fixable:     true,
+		check:       checkTrailingWhitespace,
+	},
+	{
+		Name:        "space-before-comment",
+		Description: "unquoted values should have one space before an inline comment",
+		Severity:    Info,
+		Fixable:     true,
+		check:       checkSpaceBeforeComment,
+	},
+	{
+		Name:        "duplicate-key",
+		Description: "keys should only be defined once",
+		Severity:    Warning,
+		Fixable:     true,
+		check:       checkDuplicateKey,
+	},
+	{
+		Name:        "inconsistent-export",
+		Description: "every key should have an export prefix or none should",
+		Severity:    Warning,
+		Fixable:     true,
+		check:       checkInconsistentExport,
+	},
+	{
+		Name:        "secret",
+		Description: "keys which look like secrets should not have real values",
+		Severity:    Error,
+		check:       checkSecret,
+	},
+}
+
+// Rules returns every rule in the order they are run.
+func Rules() []Rule {
+	out := make([]Rule, len(rules))
+	for i, r := range rules {
+		out[i] = *r
+	}
+	return out
+}
+
+// findRule returns the rule with the given name or nil.
+func findRule(name string) *Rule {
+	for _, r := range rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// keyColumn returns the 1-based column of the key of e on its first line,
+// after any indentation and export prefix.
+func (f *file) keyColumn(e envparse.Entry) int {
+	ln := f.lines[e.Line-1]
+	rest := strings.TrimLeft(ln, " \t")
+	if e.Export {
+		rest = strings.TrimLeft(strings.TrimPrefix(rest, "export"), " \t")
+	}
+	return len(ln) - len(rest) + 1
+}
+
+func checkLowercaseKey(f *file) []Issue {
+	issues := []Issue{}
+	for _, e := range f.entries {
+		if strings.ToUpper(e.Key) != e.Key {
+			issues = append(issues, Issue{
+				Line:    e.Line,
+				Column:  f.keyColumn(e),
+				Key:     e.Key,
+				Message: fmt.Sprintf("key %s is not uppercase", e.Key),
+			})
+		}
+	}
+	return issues
+}
+
+func checkKeyCharacters(f *file) []Issue {
+	issues := []Issue{}
+	for _, e := range f.entries {
+		if i := invalidShellChar(e.Key); i >= 0 {
+			issues = append(issues, Issue{
+				Line:    e.Line,
+				Column:  f.keyColumn(e) + i,
+				Key:     e.Key,
+				Message: fmt.Sprintf("key %s contains %q which shells do not allow", e.Key, e.Key[i]),
+			})
+		}
+	}
+	return issues
+}
+
+// invalidShellChar returns the index of the first character of key which is
+// not allowed in shell variable names or -1.
+func invalidShellChar(key string) int {
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '_':
+		case c >= 'A' && c <= 'Z':
+		case c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+func checkTrailingWhitespace(f *file) []Issue {
+	// Lines within a pair spanning multiple lines may be inside quotes
+	skip := map[int]bool{}
+	for _, e := range f.entries {
+		for n := 1; n < span(e); n++ {
+			skip[e.Line+n-1] = true
+		}
+	}
+	pairs := map[int]envparse.Entry{}
+	for _, e := range f.entries {
+		pairs[e.Line] = e
+	}
+
+	issues := []Issue{}
+	for i, ln := range f.lines {
+		line := i + 1
+		text, _ := splitCR(ln)
+		trimmed := strings.TrimRight(text, " \t")
+		if skip[line] || len(trimmed) == len(text) {
+			continue
+		}
+
+		// Some dialects keep trailing whitespace in values
+		if e, ok := pairs[line]; ok {
+			if fixed, ok := f.parseLine(trimmed); !ok || fixed.Pair != e.Pair {
+				continue
+			}
+		}
+
+		issues = append(issues, Issue{
+			Line:    line,
+			Column:  len(trimmed) + 1,
+			Message: "trailing whitespace",
+			fix:     &edit{line: line, n: 1, fn: trimTrailing},
+		})
+	}
+	return issues
+}
+
+func checkSpaceBeforeComment(f *file) []Issue {
+	issues := []Issue{}
+	for _, e := range f.entries {
+		if e.Comment == "" || span(e) > 1 || e.Quotes != envparse.Unquoted {
+			continue
+		}
+		text, _ := splitCR(f.lines[e.Line-1])
+
+		// Find the # which starts the comment
+		for i := 1; i < len(text); i++ {
+			if text[i] != '#' || (text[i-1] != ' ' && text[i-1] != '\t') {
+				continue
+			}
+			value := strings.TrimRight(text[:i], " \t")
+			if strings.TrimSpace(text[i+1:]) != e.Comment {
+				continue
+			}
+			if p, ok := f.parseLine(value); !ok || p.Pair != e.Pair {
+				continue
+			}
+
+			if i-len(value) > 1 || text[i-1] != ' ' {
+				comment := strings.TrimRight(text[i:], " \t")
+				issues = append(issues, Issue{
+					Line:    e.Line,
+					Column:  len(value) + 1,
+					Key:     e.Key,
+					Message: "whitespace before the comment is not part of the value",
+					fix: &edit{line: e.Line, n: 1, fn: func(lines []string) []string {
+						return spaceComment(lines, comment)
+					}},
+				})
+			}
+			break
+		}
+	}
+	return issues
+}
+
+func checkDuplicateKey(f *file) []Issue {
+	last := map[string]envparse.Entry{}
+	issues := []Issue{}
+	for _, e := range f.entries {
+		prev, ok := last[e.Key]
+		last[e.Key] = e
+		if !ok {
+			continue
+		}
+
+		// Parse uses the last definition so earlier ones can be removed
+		issues = append(issues, Issue{
+			Line:    e.Line,
+			Column:  f.keyColumn(e),
+			Key:     e.Key,
+			Message: fmt.Sprintf("duplicate key %s (previously defined on line %d)", e.Key, prev.Line),
+			fix: &edit{line: prev.Line, n: span(prev), fn: func(lines []string) []string {
+				return nil
+			}},
+		})
+	}
+	return issues
+}
+
+func checkInconsistentExport(f *file) []Issue {
+	if len(f.entries) == 0 {
+		return nil
+	}
+
+	// The first pair determines the style of the file
+	export := f.entries[0].Export
+	issues := []Issue{}
+	for _, e := range f.entries[1:] {
+		if e.Export == export {
+			continue
+		}
+
+		msg := fmt.Sprintf("key %s has an export prefix but line %d does not", e.Key, f.entries[0].Line)
+		fn := removeExport
+		if export {
+			msg = fmt.Sprintf("key %s has no export prefix but line %d does", e.Key, f.entries[0].Line)
+			fn = addExport
+		}
+		issues = append(issues, Issue{
+			Line:    e.Line,
+			Column:  1,
+			Key:     e.Key,
+			Message: msg,
+			fix:     &edit{line: e.Line, n: 1, fn: fn},
+		})
+	}
+	return issues
+}
+
+// Fixes are applied to the current text of the lines so several may be
+// applied to the same line in any order.
+
+// trimTrailing removes trailing whitespace from the first line.
+func trimTrailing(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	text, cr := splitCR(lines[0])
+	lines[0] = strings.TrimRight(text, " \t") + cr
+	return lines
+}
+
+// spaceComment replaces the whitespace before the last occurrence of comment
+// on the first line with a single space.
+func spaceComment(lines []string, comment string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	text, cr := splitCR(lines[0])
+	i := strings.LastIndex(text, comment)
+	if i < 0 {
+		return lines
+	}
+	lines[0] = strings.TrimRight(text[:i], " \t") + " " + text[i:] + cr
+	return lines
+}
+
+// addExport adds an export prefix to the first line.
+func addExport(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	ln := lines[0]
+	indent := len(ln) - len(strings.TrimLeft(ln, " \t"))
+	lines[0] = ln[:indent] + "export " + ln[indent:]
+	return lines
+}
+
+// removeExport removes the export prefix from the first line.
+func removeExport(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	ln := lines[0]
+	indent := len(ln) - len(strings.TrimLeft(ln, " \t"))
+	rest := strings.TrimPrefix(ln[indent:], "export")
+	lines[0] = ln[:indent] + strings.TrimLeft(rest, " \t")
+	return lines
+}
+
+// secretWords are parts of keys which are likely to hold secrets.
+var secretWords = []string{
+	"SECRET", "PASSWORD", "PASSWD", "TOKEN", "API_KEY", "APIKEY",
+	"PRIVATE_KEY", "ACCESS_KEY", "CREDENTIAL",
+}
+
+// placeholders are values which are clearly not real secrets.
+var placeholders = []string{
+	"changeme", "change_me", "change-me", "replaceme", "replace_me", "todo",
+	"example", "placeholder", "secret", "password", "none", "null", "dummy",
+}
+
+func checkSecret(f *file) []Issue {
+	issues := []Issue{}
+	for _, e := range f.entries {
+		key := strings.ToUpper(e.Key)
+		for _, word := range secretWords {
+			if strings.Contains(key, word) && !isPlaceholder(e.Val) {
+				issues = append(issues, Issue{
+					Line:    e.Line,
+					Column:  f.keyColumn(e),
+					Key:     e.Key,
+					Message: fmt.Sprintf("key %s looks like a secret and has a value", e.Key),
+				})
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// isPlaceholder returns true if v is empty, a variable reference, or an
+// obvious placeholder value.
+func isPlaceholder(v string) bool {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "":
+		return true
+	case strings.HasPrefix(v, "$"):
+		return true
+	case strings.HasPrefix(v, "<") && strings.HasSuffix(v, ">"):
+		return true
+	case strings.Trim(v, v[:1]) == "" && len(v) > 2:
+		// A repeated character such as xxx or ***
+		return true
+	}
+	for _, p := range placeholders {
+		if strings.EqualFold(v, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLine parses a single line using the options of the file.
+func (f *file) parseLine(ln string) (envparse.Entry, bool) {
+	p := envparse.NewWithOptions(strings.NewReader(ln), f.opts...)
+	e, err := p.NextEntry()
+	return e, err == nil
+}
+
+// splitCR splits a trailing carriage return from a line.
+func splitCR(ln string) (string, string) {
+	if strings.HasSuffix(ln, "\r") {
+		return ln[:len(ln)-1], "\r"
+	}
+	return ln, ""
+}
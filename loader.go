@@ -0,0 +1,191 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Source is an input to a Loader.
+type Source struct {
+	name     string
+	optional bool
+
+	// open returns the input to parse, or lookup overrides existing keys
+	open   func() (io.ReadCloser, error)
+	lookup func(string) (string, bool)
+}
+
+// FileSource returns a Source which reads the named file.
+func FileSource(name string) Source {
+	return Source{
+		name: name,
+		open: func() (io.ReadCloser, error) {
+			return os.Open(name)
+		},
+	}
+}
+
+// FSSource returns a Source which reads the named file from fsys.
+func FSSource(fsys fs.FS, name string) Source {
+	return Source{
+		name: name,
+		open: func() (io.ReadCloser, error) {
+			return fsys.Open(name)
+		},
+	}
+}
+
+// ReaderSource returns a Source which reads r. The name identifies the
+// source in errors and entries.
+func ReaderSource(name string, r io.Reader) Source {
+	return Source{
+		name: name,
+		open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(r), nil
+		},
+	}
+}
+
+// LookupSource returns a Source which overrides the value of every key
+// defined by previous sources with the value returned by lookup, if found.
+// Keys which were not defined by previous sources are never added.
+func LookupSource(name string, lookup func(string) (string, bool)) Source {
+	return Source{
+		name:   name,
+		lookup: lookup,
+	}
+}
+
+// EnvSource returns a LookupSource named "environment" which overrides keys
+// with their values from the process environment.
+func EnvSource() Source {
+	return LookupSource("environment", os.LookupEnv)
+}
+
+// Optional returns a copy of the Source which is skipped if it does not
+// exist instead of returning an error.
+func (s Source) Optional() Source {
+	s.optional = true
+	return s
+}
+
+// Name returns the name of the Source.
+func (s Source) Name() string {
+	return s.name
+}
+
+// ProfileFiles returns the conventional file names for a profile, such as
+// "development" or "production", in order of increasing precedence:
+//
+//	.env
+//	.env.<profile>
+//	.env.local
+//	.env.<profile>.local
+//
+// The .env.local file is omitted for the "test" profile so tests do not
+// depend on local overrides. An empty profile returns .env and .env.local.
+func ProfileFiles(profile string) []string {
+	if profile == "" {
+		return []string{".env", ".env.local"}
+	}
+
+	names := []string{".env", ".env." + profile}
+	if profile != "test" {
+		names = append(names, ".env.local")
+	}
+	return append(names, ".env."+profile+".local")
+}
+
+// Loader merges environment variables from an ordered list of sources, with
+// keys in later sources taking precedence over earlier ones.
+type Loader struct {
+	sources []Source
+	opts    []Option
+}
+
+// NewLoader returns a Loader which parses each source using a Parser
+// configured with the given options.
+func NewLoader(opts ...Option) *Loader {
+	return &Loader{opts: opts}
+}
+
+// Add sources to the end of the Loader's list, so they take precedence over
+// those already added.
+func (l *Loader) Add(sources ...Source) *Loader {
+	l.sources = append(l.sources, sources...)
+	return l
+}
+
+// AddProfile adds the optional ProfileFiles for profile in dir.
+func (l *Loader) AddProfile(dir, profile string) *Loader {
+	for _, name := range ProfileFiles(profile) {
+		l.Add(FileSource(filepath.Join(dir, name)).Optional())
+	}
+	return l
+}
+
+// Load reads every source in order and returns the entry which took
+// precedence for each key, in the order keys were first defined. The Source
+// of each entry is the name of the source it was read from.
+//
+// Sources which do not exist are skipped if they are optional and return an
+// error otherwise. The File of every ParseError is the name of the source it
+// occurred in. If WithCollectErrors is enabled the valid entries of every
+// source are returned along with the ParseErrors of all of them.
+func (l *Loader) Load() ([]Entry, error) {
+	entries := []Entry{}
+	index := make(map[string]int)
+	errs := ParseErrors{}
+	for _, s := range l.sources {
+		if s.lookup != nil {
+			for i := range entries {
+				if v, ok := s.lookup(entries[i].Key); ok {
+					entries[i] = Entry{Pair: Pair{Key: entries[i].Key, Val: v}, Source: s.name}
+				}
+			}
+			continue
+		}
+
+		found, err := s.load(l.opts)
+		if perrs, ok := err.(ParseErrors); ok {
+			errs = append(errs, perrs...)
+		} else if err != nil {
+			return nil, err
+		}
+		for _, e := range found {
+			if i, ok := index[e.Key]; ok {
+				entries[i] = e
+				continue
+			}
+			index[e.Key] = len(entries)
+			entries = append(entries, e)
+		}
+	}
+	if len(errs) > 0 {
+		return entries, errs
+	}
+	return entries, nil
+}
+
+// load parses every entry of the source. Returns no entries if the source is
+// optional and does not exist.
+func (s Source) load(opts []Option) ([]Entry, error) {
+	r, err := s.open()
+	if err != nil {
+		if s.optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	opts = append(opts[:len(opts):len(opts)], WithSource(s.name))
+	return parseEntries(NewWithOptions(r, opts...))
+}
@@ -0,0 +1,129 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProfileFiles(t *testing.T) {
+	cases := []struct {
+		profile string
+		names   []string
+	}{
+		{"", []string{".env", ".env.local"}},
+		{"development", []string{".env", ".env.development", ".env.local", ".env.development.local"}},
+		{"test", []string{".env", ".env.test", ".env.test.local"}},
+	}
+
+	for _, c := range cases {
+		if names := ProfileFiles(c.profile); !reflect.DeepEqual(names, c.names) {
+			t.Errorf("expected %v for %q but found %v", c.names, c.profile, names)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		".env":             {Data: []byte("A=base\nB=base\nC=base\n")},
+		".env.development": {Data: []byte("B=dev\n")},
+	}
+	lookup := func(k string) (string, bool) {
+		if k == "C" || k == "X" {
+			return "env", true
+		}
+		return "", false
+	}
+
+	entries, err := NewLoader().Add(
+		FSSource(fsys, ".env"),
+		FSSource(fsys, ".env.development"),
+		FSSource(fsys, ".env.local").Optional(),
+		ReaderSource("overrides", strings.NewReader("D=1\nA=over\n")),
+		LookupSource("env", lookup),
+	).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		pair   Pair
+		source string
+		line   int
+	}{
+		{Pair{"A", "over"}, "overrides", 2},
+		{Pair{"B", "dev"}, ".env.development", 1},
+		{Pair{"C", "env"}, "env", 0},
+		{Pair{"D", "1"}, "overrides", 1},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries but found %d: %#v", len(expected), len(entries), entries)
+	}
+	for i, exp := range expected {
+		if e := entries[i]; e.Pair != exp.pair || e.Source != exp.source || e.Line != exp.line {
+			t.Errorf("expected %v from %s:%d but found %v from %s:%d", exp.pair, exp.source, exp.line, e.Pair, e.Source, e.Line)
+		}
+	}
+}
+
+func TestLoader_AddProfile(t *testing.T) {
+	names, dir := writeFiles(t, "A=1\nB=1\n", "B=2\n")
+	defer os.RemoveAll(dir)
+	if err := os.Rename(names[0], filepath.Join(dir, ".env")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.Rename(names[1], filepath.Join(dir, ".env.prod.local")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := NewLoader().AddProfile(dir, "prod").Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Pair != (Pair{"A", "1"}) || entries[1].Pair != (Pair{"B", "2"}) {
+		t.Errorf("unexpected entries: %#v", entries)
+	}
+	if entries[1].Source != filepath.Join(dir, ".env.prod.local") {
+		t.Errorf("unexpected source: %s", entries[1].Source)
+	}
+}
+
+func TestLoader_Err(t *testing.T) {
+	fsys := fstest.MapFS{
+		".env": {Data: []byte("A=1\nB\nC\n")},
+	}
+
+	_, err := NewLoader().Add(FSSource(fsys, ".env.local")).Load()
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not exist error but found %v", err)
+	}
+
+	entries, err := NewLoader(WithCollectErrors()).
+		Add(FSSource(fsys, ".env"), ReaderSource("extra", strings.NewReader("x\nD=4\nA=2\n"))).
+		Load()
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 3 || errs[0].File != ".env" || errs[2].File != "extra" {
+		t.Errorf("expected 3 ParseErrors but found %v", err)
+	}
+	found := []Pair{}
+	for _, e := range entries {
+		found = append(found, e.Pair)
+	}
+	if expected := []Pair{{"A", "2"}, {"D", "4"}}; !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected %v but found %v", expected, found)
+	}
+
+	_, err = NewLoader().Add(FSSource(fsys, ".env")).Load()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("expected a ParseError on line 2 but found %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnterminatedHeredoc = fmt.Errorf("unterminated heredoc")
+)
+
+var heredocMarker = []byte("<<")
+
+// segment is a physical line within a multiline value.
+type segment struct {
+	// start of the line within the joined value
+	start int
+
+	line int
+	off  int
+}
+
+// parseLine parses the current line of the Parser, reading subsequent lines
+// for multiline values if enabled. Errors are returned as ParseErrors. If info
+// is non-nil it is populated with details of the line including its raw
+// bytes.
+func (p *Parser) parseLine(ln []byte, info *lineInfo) ([]byte, []byte, error) {
+	switch p.opts.dialect {
+	case DialectDocker:
+		return p.parseDockerLine(ln, info)
+	case DialectSystemd:
+		return p.parseSystemdLine(ln, info)
+	case DialectDotenv:
+		return p.parseDotenvLine(ln, info)
+	}
+	if p.opts.heredocs {
+		if key, delim, ok := splitHeredoc(ln); ok {
+			return p.parseHeredoc(ln, key, delim, info)
+		}
+	}
+
+	k, v, err := parseLineWith(ln, &p.opts, p.ex, info)
+	if !p.opts.multiline || !isUnmatched(err) {
+		if err != nil {
+			return nil, nil, lineParseError(p.i, p.off, ln, err)
+		}
+		if info != nil {
+			info.raw = append([]byte{}, ln...)
+		}
+		return k, v, nil
+	}
+
+	// Keep appending lines until the quotes are matched
+	segs := []segment{{start: 0, line: p.i, off: p.off}}
+	buf := append([]byte{}, ln...)
+	for isUnmatched(err) {
+		if !p.scan() {
+			if serr := p.scanErr(); serr != nil {
+				return nil, nil, serr
+			}
+			break
+		}
+
+		buf = append(buf, '\n')
+		segs = append(segs, segment{start: len(buf), line: p.i, off: p.off})
+		buf = append(buf, p.s.Bytes()...)
+		k, v, err = parseLineWith(buf, &p.opts, p.ex, info)
+	}
+	if err != nil {
+		return nil, nil, segmentError(buf, segs, err)
+	}
+	if info != nil {
+		info.raw = buf
+	}
+	return k, v, nil
+}
+
+// isUnmatched returns true if err is due to an unmatched quote.
+func isUnmatched(err error) bool {
+	return errors.Is(err, ErrUnmatchedDouble) || errors.Is(err, ErrUnmatchedSingle)
+}
+
+// segmentError returns a ParseError for an error within a multiline value
+// positioned on the physical line the error occurred on.
+func segmentError(buf []byte, segs []segment, err error) error {
+	seg := 0
+	if lerr, ok := err.(*lineError); ok {
+		for seg < len(segs)-1 && segs[seg+1].start <= lerr.off {
+			seg++
+		}
+		lerr.off -= segs[seg].start
+	}
+
+	text := buf[segs[seg].start:]
+	if i := bytes.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	text = bytes.TrimSuffix(text, []byte{'\r'})
+	return lineParseError(segs[seg].line, segs[seg].off, text, err)
+}
+
+// splitHeredoc returns the raw key and delimiter of a KEY<<DELIM line.
+func splitHeredoc(ln []byte) ([]byte, []byte, bool) {
+	if trimmed := bytes.TrimSpace(ln); len(trimmed) > 0 && trimmed[0] == '#' {
+		return nil, nil, false
+	}
+	i := bytes.Index(ln, heredocMarker)
+	if i < 0 {
+		return nil, nil, false
+	}
+	if eq := bytes.IndexByte(ln, '='); eq >= 0 && eq < i {
+		// << is part of a regular value
+		return nil, nil, false
+	}
+	return ln[:i], bytes.TrimSpace(ln[i+len(heredocMarker):]), true
+}
+
+// parseHeredoc reads the lines of a heredoc up to its delimiter. The raw key
+// and delim are subslices of ln.
+func (p *Parser) parseHeredoc(ln, rawKey, delim []byte, info *lineInfo) ([]byte, []byte, error) {
+	start, startOff := p.i, p.off
+	at := func(b []byte) int {
+		return cap(ln) - cap(b)
+	}
+	marker := len(rawKey)
+
+	key, bad, err := p.opts.cleanKey(rawKey)
+	if err != nil {
+		if len(key) == 0 {
+			return nil, nil, lineParseError(start, startOff, ln, errAt(marker, nil, err))
+		}
+		return nil, nil, lineParseError(start, startOff, ln, errAt(at(key)+bad, nil, err))
+	}
+
+	quoted := false
+	if n := len(delim); n >= 2 && (delim[0] == '\'' || delim[0] == '"') && delim[n-1] == delim[0] {
+		delim = delim[1 : n-1]
+		quoted = true
+	}
+	if err := checkKey(delim); err != nil {
+		derr := fmt.Errorf("invalid heredoc delimiter %q", delim)
+		return nil, nil, lineParseError(start, startOff, ln, errAt(marker, key, derr))
+	}
+	if info != nil {
+		*info = lineInfo{
+			quotes: Heredoc,
+			export: hasExport(rawKey) && !p.opts.noExport,
+		}
+	}
+	text := append([]byte{}, ln...)
+	delim = append([]byte{}, delim...)
+	key = append([]byte{}, key...)
+
+	var raw []byte
+	if info != nil {
+		raw = append(raw, text...)
+	}
+
+	val := []byte{}
+	for n := 0; ; n++ {
+		if !p.scan() {
+			if err := p.scanErr(); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, lineParseError(start, startOff, text, errAt(marker, key, ErrUnterminatedHeredoc))
+		}
+
+		ln := p.s.Bytes()
+		if info != nil {
+			raw = append(append(raw, '\n'), ln...)
+		}
+		if bytes.Equal(bytes.TrimSpace(ln), delim) {
+			break
+		}
+		if n > 0 {
+			val = append(val, '\n')
+		}
+		val = append(val, ln...)
+	}
+
+	if p.ex != nil && !quoted {
+		val, err = p.ex.expandAll(val)
+		if err != nil {
+			perr := lineParseError(start, startOff, text, err).(*ParseError)
+			perr.Key = string(key)
+			return nil, nil, perr
+		}
+	}
+	if info != nil {
+		info.raw = raw
+	}
+	return key, val, nil
+}
@@ -0,0 +1,127 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testPEM = `-----BEGIN CERTIFICATE-----
+MIIBszCCAVmgAwIBAgIUY
+-----END CERTIFICATE-----`
+
+func TestParse_Multiline(t *testing.T) {
+	buf := `A=1
+CERT="` + testPEM + `"
+SINGLE='first
+  second' # comment
+MIXED="a
+b"'c
+d'
+B=2
+`
+	env, err := ParseWithOptions(strings.NewReader(buf), WithMultiline())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"A":      "1",
+		"CERT":   testPEM,
+		"SINGLE": "first\n  second",
+		"MIXED":  "a\nbc\nd",
+		"B":      "2",
+	}
+	if len(env) != len(expected) {
+		t.Errorf("expected %d keys but found %d: %#v", len(expected), len(env), env)
+	}
+	for k, v := range expected {
+		if env[k] != v {
+			t.Errorf("expected %s=%q but found %q", k, v, env[k])
+		}
+	}
+
+	// Without the option multiline values are still an error
+	if _, err := Parse(strings.NewReader(buf)); !errors.Is(err, ErrUnmatchedDouble) {
+		t.Errorf("expected %v but found %v", ErrUnmatchedDouble, err)
+	}
+}
+
+func TestParse_Heredoc(t *testing.T) {
+	buf := `NAME=world
+# KEY<<NOT
+export CERT<<EOF
+` + testPEM + `
+EOF
+GREETING<<END
+  hello ${NAME}
+END
+LITERAL<<'END'
+hello ${NAME}
+  END
+EMPTY<<EOF
+EOF
+CMP=a<<b
+`
+	env, err := ParseWithOptions(strings.NewReader(buf), WithHeredocs(), WithInterpolation(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"NAME":     "world",
+		"CERT":     testPEM,
+		"GREETING": "  hello world",
+		"LITERAL":  "hello ${NAME}",
+		"CMP":      "a<<b",
+	}
+	if len(env) != len(expected) {
+		t.Errorf("expected %d keys but found %d: %#v", len(expected), len(env), env)
+	}
+	for k, v := range expected {
+		if env[k] != v {
+			t.Errorf("expected %s=%q but found %q", k, v, env[k])
+		}
+	}
+}
+
+func TestParse_Multiline_Err(t *testing.T) {
+	cases := []struct {
+		name string
+		buf  string
+		opts []Option
+		n    int
+		err  error
+	}{
+		{"UnterminatedDouble", "A=1\nB=\"foo\nbar\nC=3\n", []Option{WithMultiline()}, 2, ErrUnmatchedDouble},
+		{"UnterminatedSingle", "A=1\n\nB='foo\nbar", []Option{WithMultiline()}, 3, ErrUnmatchedSingle},
+		{"InvalidEscape", "A=\"foo\nbar\\q\"", []Option{WithMultiline()}, 2, nil},
+		{"ControlChar", "A='foo\n\x01'", []Option{WithMultiline()}, 2, nil},
+		{"UnterminatedHeredoc", "A=1\nB<<EOF\nfoo\n", []Option{WithHeredocs()}, 2, ErrUnterminatedHeredoc},
+		{"InvalidHeredocKey", "1B<<EOF\nEOF\n", []Option{WithHeredocs()}, 1, nil},
+		{"InvalidHeredocDelim", "B<<\nfoo\n", []Option{WithHeredocs()}, 1, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), c.opts...)
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected a *envparse.ParseError but found %T", err)
+			}
+			if perr.Line != c.n {
+				t.Errorf("expected error on line %d but found %d: %v", c.n, perr.Line, err)
+			}
+			if c.err != nil && perr.Err != c.err {
+				t.Errorf("expected %v but found %v", c.err, perr.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import "io"
+
+// maxInt is the largest int, used as the scanner buffer size for unbounded
+// lines.
+const maxInt = int(^uint(0) >> 1)
+
+// Option configures a Parser.
+type Option func(*options)
+
+// options are the configurable behaviors of a Parser. The zero value matches
+// the behavior of New.
+type options struct {
+	interpolate bool
+	lookup      func(string) (string, bool)
+	strict      bool
+	multiline   bool
+	heredocs    bool
+
+	checkKey         func(string) error
+	controlChars     bool
+	noInlineComments bool
+	noExport         bool
+	emptyValues      bool
+
+	// maxLineSize of 0 uses the bufio.Scanner default and -1 is unbounded
+	maxLineSize int
+
+	collectErrors bool
+	onError       func(*ParseError) error
+
+	duplicates DuplicatePolicy
+	onWarning  func(error)
+
+	source string
+
+	dialect Dialect
+	inherit func(string) (string, bool)
+}
+
+// defaultOptions are used by parseLine and match the behavior of New.
+var defaultOptions = options{}
+
+// WithInterpolation enables expansion of $VAR, ${VAR}, ${VAR:-default}, and
+// ${VAR:?error} references in unquoted and double quoted values. Single
+// quoted values are never expanded. A literal $ may be escaped as \$ within
+// double quotes.
+//
+// References are resolved against keys defined on previous lines of the input
+// and then lookup, which may be nil. Pass os.LookupEnv to fall back to the
+// process environment. Undefined variables expand to an empty string unless
+// WithStrictInterpolation is also used.
+//
+// Values are expanded as they are parsed, so a reference to a key on the same
+// or a later line (including a key referencing itself) resolves to its
+// previous definition and cycles cannot occur.
+func WithInterpolation(lookup func(string) (string, bool)) Option {
+	return func(o *options) {
+		o.interpolate = true
+		o.lookup = lookup
+	}
+}
+
+// WithStrictInterpolation makes references to undefined variables an error
+// when interpolation is enabled.
+func WithStrictInterpolation() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithMultiline allows double and single quoted values to span multiple lines
+// until their closing quote. Newlines within the quotes are kept in the value.
+// Unterminated values are reported on the line the value started.
+func WithMultiline() Option {
+	return func(o *options) {
+		o.multiline = true
+	}
+}
+
+// WithHeredocs enables heredoc style values of the form:
+//
+//	KEY<<EOF
+//	first line
+//	second line
+//	EOF
+//
+// Every line between the first and the line containing only the delimiter is
+// kept as-is, joined by newlines, without a trailing newline. If
+// interpolation is enabled variable references are expanded unless the
+// delimiter is quoted as in KEY<<'EOF'.
+func WithHeredocs() Option {
+	return func(o *options) {
+		o.heredocs = true
+	}
+}
+
+// WithKeyCheck replaces the default key validation, which requires keys to
+// be of the form [A-Za-z_][A-Za-z0-9/_.]*, with check. Keys are passed to
+// check after whitespace and any export prefix have been removed.
+func WithKeyCheck(check func(key string) error) Option {
+	return func(o *options) {
+		o.checkKey = check
+	}
+}
+
+// WithControlChars allows control characters such as tabs in values instead
+// of returning an error.
+func WithControlChars() Option {
+	return func(o *options) {
+		o.controlChars = true
+	}
+}
+
+// WithoutInlineComments treats # within unquoted values as part of the value
+// instead of the start of a comment. Lines beginning with # are still
+// comments.
+func WithoutInlineComments() Option {
+	return func(o *options) {
+		o.noInlineComments = true
+	}
+}
+
+// WithoutExportPrefix disables removal of the "export " prefix from keys, so
+// lines such as "export FOO=bar" are invalid under the default key check.
+func WithoutExportPrefix() Option {
+	return func(o *options) {
+		o.noExport = true
+	}
+}
+
+// WithEmptyValues returns keys with empty values, such as FOO= or FOO="",
+// instead of skipping them.
+func WithEmptyValues() Option {
+	return func(o *options) {
+		o.emptyValues = true
+	}
+}
+
+// WithMaxLineSize sets the maximum length in bytes of a line, excluding its
+// line ending, instead of the default of bufio.MaxScanTokenSize. Longer lines
+// return a ParseError wrapping ErrLineTooLong. A size less than or equal to 0
+// removes the limit entirely.
+func WithMaxLineSize(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = -1
+		}
+		o.maxLineSize = n
+	}
+}
+
+// WithCollectErrors skips lines which cannot be parsed instead of stopping at
+// the first error. The errors for skipped lines are returned by
+// Parser.Errors, and Parse and ParsePairs return every valid pair along with
+// a ParseErrors. Errors reading the input always stop parsing.
+func WithCollectErrors() Option {
+	return func(o *options) {
+		o.collectErrors = true
+	}
+}
+
+// WithOnError calls fn for every line which cannot be parsed. If fn returns
+// nil the line is skipped, otherwise parsing stops and the returned error is
+// returned by the Parser. Skipped errors are only kept if WithCollectErrors is
+// also enabled.
+func WithOnError(fn func(*ParseError) error) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// WithDuplicates sets how keys defined more than once are handled. The
+// default is DuplicateLastWins.
+func WithDuplicates(policy DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicates = policy
+	}
+}
+
+// WithOnWarning calls fn for problems which do not stop parsing, such as
+// duplicate keys when using DuplicateWarn.
+func WithOnWarning(fn func(error)) Option {
+	return func(o *options) {
+		o.onWarning = fn
+	}
+}
+
+// WithSource sets the name of the input, such as a file name, which is
+// included in every Entry and ParseError.
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithDialect sets the syntax used to parse each line. The default is
+// DialectDefault.
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.dialect = d
+	}
+}
+
+// WithInherit sets the lookup used for keys which inherit their value from
+// the host environment, such as bare KEY lines in DialectDocker. Pass
+// os.LookupEnv to use the process environment. Keys which are not found are
+// skipped.
+func WithInherit(lookup func(string) (string, bool)) Option {
+	return func(o *options) {
+		o.inherit = lookup
+	}
+}
+
+// NewWithOptions returns a new environment variable Parser from an input
+// reader configured with the given options.
+func NewWithOptions(r io.Reader, opts ...Option) *Parser {
+	p := New(r)
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+	switch n := p.opts.maxLineSize; {
+	case n < 0:
+		p.s.Buffer(nil, maxInt)
+	case n > 0:
+		// Leave room for a \r\n line ending; longer lines are caught by scan
+		p.s.Buffer(nil, n+2)
+	}
+	switch p.opts.dialect {
+	case DialectDocker, DialectSystemd, DialectDotenv:
+		p.opts.emptyValues = true
+	}
+	if p.opts.interpolate {
+		p.ex = &expander{
+			vars:   make(map[string]string),
+			lookup: p.opts.lookup,
+			strict: p.opts.strict,
+		}
+	}
+	return p
+}
+
+// ParseWithOptions parses environment variables from an io.Reader into a map
+// using a Parser configured with the given options. If WithCollectErrors is
+// enabled the valid pairs are returned along with any ParseErrors.
+func ParseWithOptions(r io.Reader, opts ...Option) (map[string]string, error) {
+	return parse(NewWithOptions(r, opts...))
+}
+
+// ParsePairsWithOptions parses environment variables from an io.Reader into a
+// slice of deduplicated key/value pairs using a Parser configured with the
+// given options. If WithCollectErrors is enabled the valid pairs are returned
+// along with any ParseErrors.
+func ParsePairsWithOptions(r io.Reader, opts ...Option) ([]Pair, error) {
+	return parsePairs(NewWithOptions(r, opts...))
+}
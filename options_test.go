@@ -0,0 +1,105 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseWithOptions(t *testing.T) {
+	upper := func(key string) error {
+		if strings.ToUpper(key) != key {
+			return fmt.Errorf("key must be uppercase: %q", key)
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name string
+		buf  string
+		opts []Option
+		env  map[string]string
+	}{
+		{"Defaults", "export A=1 # c", nil, map[string]string{"A": "1"}},
+		{"KeyCheck", "FOO-BAR=1\n1X=2", []Option{WithKeyCheck(upper)}, map[string]string{"FOO-BAR": "1", "1X": "2"}},
+		{"ControlChars", "A=a\tb\nB='\x01'", []Option{WithControlChars()}, map[string]string{"A": "a\tb", "B": "\x01"}},
+		{"NoInlineComments", "# full line\nA=a#b # c\nB=\"#\" #", []Option{WithoutInlineComments()}, map[string]string{"A": "a#b # c", "B": "# #"}},
+		{"NoExportPrefix", "export=1", []Option{WithoutExportPrefix()}, map[string]string{"export": "1"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), c.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(env) != len(c.env) {
+				t.Errorf("expected %d keys but found %d: %#v", len(c.env), len(env), env)
+			}
+			for k, v := range c.env {
+				if env[k] != v {
+					t.Errorf("expected %s=%q but found %q", k, v, env[k])
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithOptions_Err(t *testing.T) {
+	errLower := errors.New("lowercase")
+	lower := func(key string) error {
+		if strings.ToUpper(key) != key {
+			return errLower
+		}
+		return nil
+	}
+	permissive := func(string) error { return nil }
+
+	cases := []struct {
+		name string
+		buf  string
+		opts []Option
+		err  error
+	}{
+		{"KeyCheck", "A=1\nb=2", []Option{WithKeyCheck(lower)}, errLower},
+		{"KeyCheckExport", "export A=1\nexport b=2", []Option{WithKeyCheck(lower)}, errLower},
+		{"KeyCheckEmpty", "=1", []Option{WithKeyCheck(permissive)}, ErrEmptyKey},
+		{"KeyCheckEmptyValue", "=", []Option{WithKeyCheck(permissive), WithEmptyValues()}, ErrEmptyKey},
+		{"NoExportPrefix", "export A=1", []Option{WithoutExportPrefix()}, nil},
+		{"ControlChars", "A=a\tb", nil, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := ParseWithOptions(strings.NewReader(c.buf), c.opts...)
+			if err == nil {
+				t.Fatalf("expected an error but found %#v", env)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v but found %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParsePairsWithOptions(t *testing.T) {
+	buf := "A=1\nB=${A}2\nA=3\n"
+	pairs, err := ParsePairsWithOptions(strings.NewReader(buf), WithInterpolation(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Pair{{"B", "12"}, {"A", "3"}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("expected %d pairs but found %d: %#v", len(expected), len(pairs), pairs)
+	}
+	for i := range expected {
+		if pairs[i] != expected[i] {
+			t.Errorf("expected %v but found %v", expected[i], pairs[i])
+		}
+	}
+}
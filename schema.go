@@ -0,0 +1,343 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownKey   = fmt.Errorf("unknown key")
+	ErrInvalidValue = fmt.Errorf("invalid value")
+)
+
+// Type is the type a value must be convertible to.
+type Type uint8
+
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeBool
+	TypeDuration
+	TypeFloat
+)
+
+var typeNames = []string{"string", "int", "bool", "duration", "float"}
+
+func (t Type) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return fmt.Sprintf("Type(%d)", t)
+}
+
+// check returns an error if v cannot be converted to the type.
+func (t Type) check(v string) error {
+	var err error
+	switch t {
+	case TypeInt:
+		_, err = strconv.ParseInt(v, 0, 64)
+	case TypeBool:
+		_, err = strconv.ParseBool(v)
+	case TypeDuration:
+		_, err = time.ParseDuration(v)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(v, 64)
+	}
+	return err
+}
+
+// KeySchema declares the requirements for a single key.
+type KeySchema struct {
+	Name string
+
+	// Required keys must be defined with a non-empty value. Default is the
+	// value of optional keys which are not defined.
+	Required bool
+	Default  string
+
+	// Type, Pattern, and Enum restrict non-empty values. Pattern must match
+	// somewhere in the value so use ^ and $ to match the whole value.
+	Type    Type
+	Pattern *regexp.Regexp
+	Enum    []string
+}
+
+// Schema declares the keys an input may define. Keys which are not declared
+// are a violation unless AllowUnknown is set.
+type Schema struct {
+	Keys         []KeySchema
+	AllowUnknown bool
+}
+
+// key returns the declaration of name or nil if it is not declared.
+func (s *Schema) key(name string) *KeySchema {
+	for i := range s.Keys {
+		if s.Keys[i].Name == name {
+			return &s.Keys[i]
+		}
+	}
+	return nil
+}
+
+// Defaults returns entries followed by an entry for every optional key with a
+// default which entries do not define with a non-empty value.
+func (s *Schema) Defaults(entries []Entry) []Entry {
+	defined := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		defined[e.Key] = e.Val != ""
+	}
+
+	out := append([]Entry{}, entries...)
+	for _, k := range s.Keys {
+		if !k.Required && k.Default != "" && !defined[k.Name] {
+			out = append(out, Entry{Pair: Pair{Key: k.Name, Val: k.Default}})
+		}
+	}
+	return out
+}
+
+// Violation is an entry which does not satisfy a Schema. Line is the line
+// the key was defined on or 0 if it is missing.
+type Violation struct {
+	Key  string
+	File string
+	Line int
+	Err  error
+}
+
+func (v *Violation) Error() string {
+	pos := v.File
+	if v.Line > 0 {
+		pos += fmt.Sprintf(":%d", v.Line)
+	}
+	if pos != "" {
+		return fmt.Sprintf("%s: %s: %v", pos, v.Key, v.Err)
+	}
+	return fmt.Sprintf("%s: %v", v.Key, v.Err)
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Validate returns every violation of the schema by entries, such as those
+// returned by Loader.Load, in the order keys are defined followed by missing
+// required keys in the order they are declared. If a key is defined more
+// than once the last entry is checked. Empty values are treated as undefined.
+func Validate(entries []Entry, schema *Schema) []*Violation {
+	last := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		last[e.Key] = e
+	}
+
+	violations := []*Violation{}
+	for _, e := range entries {
+		if last[e.Key].Line != e.Line || last[e.Key].Source != e.Source {
+			continue
+		}
+
+		k := schema.key(e.Key)
+		var err error
+		switch {
+		case k == nil && !schema.AllowUnknown:
+			err = ErrUnknownKey
+		case k == nil || e.Val == "":
+		default:
+			err = k.check(e.Val)
+		}
+		if err != nil {
+			violations = append(violations, &Violation{Key: e.Key, File: e.Source, Line: e.Line, Err: err})
+		}
+	}
+
+	for _, k := range schema.Keys {
+		if e, ok := last[k.Name]; k.Required && (!ok || e.Val == "") {
+			violations = append(violations, &Violation{Key: k.Name, Err: ErrMissingRequired})
+		}
+	}
+	return violations
+}
+
+// check returns an error if v does not satisfy the declaration.
+func (k *KeySchema) check(v string) error {
+	if err := k.Type.check(v); err != nil {
+		return fmt.Errorf("%w: not a valid %s", ErrInvalidValue, k.Type)
+	}
+	if k.Pattern != nil && !k.Pattern.MatchString(v) {
+		return fmt.Errorf("%w: does not match %s", ErrInvalidValue, k.Pattern)
+	}
+	if len(k.Enum) == 0 {
+		return nil
+	}
+	for _, allowed := range k.Enum {
+		if v == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: must be one of %s", ErrInvalidValue, strings.Join(k.Enum, ", "))
+}
+
+// ParseSchema reads a Schema from an annotated example file such as
+// .env.example using a Parser configured with the given options. Every key
+// defined in the file is declared, and its value is ignored. Keys are
+// required unless annotated otherwise in comments on the lines directly
+// before the key or inline:
+//
+//	# @type int @default 8080
+//	PORT=
+//	# Minimum severity to log
+//	LOG_LEVEL=info # @optional @enum debug,info,warn,error
+//
+// The annotations are:
+//
+//   - @required: the key must be defined, which is the default.
+//   - @optional: the key may be omitted.
+//   - @default value: the key is optional with a default of value.
+//   - @type name: the value must be a string, int, bool, duration, or float.
+//   - @enum a,b,c: the value must be one of the comma separated values.
+//   - @pattern regexp: the whole value must match the regular expression.
+//
+// The value of each annotation ends at the next word starting with @.
+// Comments which do not start with @ are ignored. If WithCollectErrors is
+// enabled a Schema of the valid keys is returned along with a ParseError for
+// every line which could not be parsed and every invalid annotation.
+func ParseSchema(r io.Reader, opts ...Option) (*Schema, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts[:len(opts):len(opts)], WithEmptyValues())
+	p := NewWithOptions(bytes.NewReader(buf), opts...)
+	entries, err := parseEntries(p)
+	errs, _ := err.(ParseErrors)
+	if err != nil && errs == nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(buf), "\n")
+	schema := &Schema{}
+	for _, e := range entries {
+		k, perr := parseKeySchema(e, lines)
+		if perr != nil {
+			if !p.opts.collectErrors {
+				return nil, perr
+			}
+			errs = append(errs, perr)
+			continue
+		}
+		schema.Keys = append(schema.Keys, k)
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			return errs[i].Line < errs[j].Line
+		})
+		return schema, errs
+	}
+	return schema, nil
+}
+
+// parseKeySchema returns the declaration of the entry from its annotations.
+func parseKeySchema(e Entry, lines []string) (KeySchema, *ParseError) {
+	k := KeySchema{Name: e.Key}
+	optional, required := false, false
+
+	// Comment lines directly before the key followed by the inline comment
+	comments := []int{}
+	for n := e.Line - 1; n >= 1 && strings.HasPrefix(strings.TrimSpace(lines[n-1]), "#"); n-- {
+		comments = append([]int{n}, comments...)
+	}
+	comments = append(comments, e.Line)
+
+	for _, n := range comments {
+		comment := e.Comment
+		if n != e.Line {
+			comment = strings.TrimPrefix(strings.TrimSpace(lines[n-1]), "#")
+		}
+		for _, a := range annotations(comment) {
+			var err error
+			switch a[0] {
+			case "@required":
+				required = true
+			case "@optional":
+				optional = true
+			case "@default":
+				optional = true
+				k.Default = a[1]
+			case "@type":
+				k.Type, err = parseType(a[1])
+			case "@enum":
+				k.Enum = strings.Split(a[1], ",")
+			case "@pattern":
+				k.Pattern, err = regexp.Compile("^(?:" + a[1] + ")$")
+			default:
+				err = fmt.Errorf("unknown annotation %s", a[0])
+			}
+			if err != nil {
+				return k, schemaError(e, n, lines[n-1], err)
+			}
+		}
+	}
+
+	if optional && required {
+		return k, schemaError(e, e.Line, lines[e.Line-1], fmt.Errorf("key is both required and optional"))
+	}
+	k.Required = !optional
+	return k, nil
+}
+
+// annotations splits a comment into annotations and their values. Returns
+// nil if the comment does not start with an annotation.
+func annotations(comment string) [][2]string {
+	words := strings.Fields(comment)
+	if len(words) == 0 || !strings.HasPrefix(words[0], "@") {
+		return nil
+	}
+
+	found := [][2]string{}
+	for _, w := range words {
+		if strings.HasPrefix(w, "@") {
+			found = append(found, [2]string{w, ""})
+			continue
+		}
+		a := &found[len(found)-1]
+		if a[1] != "" {
+			a[1] += " "
+		}
+		a[1] += w
+	}
+	return found
+}
+
+// parseType returns the Type named by s.
+func parseType(s string) (Type, error) {
+	for i, name := range typeNames {
+		if s == name {
+			return Type(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown type %q", s)
+}
+
+// schemaError returns a ParseError for an invalid annotation of the entry on
+// line n.
+func schemaError(e Entry, n int, text string, err error) *ParseError {
+	return &ParseError{
+		File: e.Source,
+		Line: n,
+		Key:  e.Key,
+		Text: strings.TrimSuffix(text, "\r"),
+		Err:  err,
+	}
+}
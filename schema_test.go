@@ -0,0 +1,164 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testSchema = `# Application settings
+# @type int @default 8080
+PORT=
+
+# Minimum severity to log
+LOG_LEVEL=info # @optional @enum debug,info,warn
+# @pattern [a-z]+://.*
+DATABASE_URL=postgres://localhost
+API_TOKEN=changeme
+`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &Schema{Keys: []KeySchema{
+		{Name: "PORT", Default: "8080", Type: TypeInt},
+		{Name: "LOG_LEVEL", Enum: []string{"debug", "info", "warn"}},
+		{Name: "DATABASE_URL", Required: true, Pattern: regexp.MustCompile("^(?:[a-z]+://.*)$")},
+		{Name: "API_TOKEN", Required: true},
+	}}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("expected %+v but found %+v", expected, schema)
+	}
+}
+
+func TestParseSchema_Err(t *testing.T) {
+	cases := []struct {
+		name string
+		buf  string
+		line int
+		err  string
+	}{
+		{"UnknownAnnotation", "A=1\n# @secret\nB=2\n", 2, "unknown annotation @secret"},
+		{"UnknownType", "A=1 # @type uint\n", 1, `unknown type "uint"`},
+		{"InvalidPattern", "# @pattern (\nA=1\n", 1, "missing closing )"},
+		{"RequiredOptional", "# @required\nA=1 # @optional\n", 2, "key is both required and optional"},
+		{"Parse", "A=1\nB\n", 2, "missing ="},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseSchema(strings.NewReader(c.buf), WithSource(".env.example"))
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected a *ParseError but found %T: %v", err, err)
+			}
+			if perr.File != ".env.example" || perr.Line != c.line {
+				t.Errorf("expected error on line %d but found %d", c.line, perr.Line)
+			}
+			if !strings.Contains(perr.Err.Error(), c.err) {
+				t.Errorf("expected %q but found %q", c.err, perr.Err)
+			}
+		})
+	}
+}
+
+func TestParseSchema_CollectErrors(t *testing.T) {
+	buf := "# @nope\nA=1\nB\nC=1 # @optional\n"
+	schema, err := ParseSchema(strings.NewReader(buf), WithCollectErrors())
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 2 || errs[0].Line != 1 || errs[1].Line != 3 {
+		t.Fatalf("expected errors on lines 1 and 3 but found %v", err)
+	}
+	if expected := []KeySchema{{Name: "C"}}; !reflect.DeepEqual(schema.Keys, expected) {
+		t.Errorf("expected %+v but found %+v", expected, schema.Keys)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := ParseSchema(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		buf        string
+		unknown    bool
+		violations []string
+	}{
+		{"Valid", "API_TOKEN=x\nDATABASE_URL=mysql://db\n", false, nil},
+		{"Missing", "PORT=80\nAPI_TOKEN=\n", false, []string{
+			"DATABASE_URL: missing required key",
+			"API_TOKEN: missing required key",
+		}},
+		{"Invalid", "API_TOKEN=x\nPORT=http\nLOG_LEVEL=trace\nDATABASE_URL=localhost\n", false, []string{
+			".env:2: PORT: invalid value: not a valid int",
+			".env:3: LOG_LEVEL: invalid value: must be one of debug, info, warn",
+			".env:4: DATABASE_URL: invalid value: does not match ^(?:[a-z]+://.*)$",
+		}},
+		{"Unknown", "API_TOKEN=x\nDATABASE_URL=a://b\nDEBUG=1\n", false, []string{
+			".env:3: DEBUG: unknown key",
+		}},
+		{"AllowUnknown", "API_TOKEN=x\nDATABASE_URL=a://b\nDEBUG=1\n", true, nil},
+		{"Duplicate", "API_TOKEN=x\nDATABASE_URL=a://b\nPORT=x\nPORT=1\n", false, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries, err := parseEntries(NewWithOptions(strings.NewReader(c.buf), WithSource(".env"), WithEmptyValues()))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			schema.AllowUnknown = c.unknown
+			found := []string{}
+			for _, v := range Validate(entries, schema) {
+				found = append(found, v.Error())
+			}
+			if len(found) != len(c.violations) || (len(found) > 0 && !reflect.DeepEqual(found, c.violations)) {
+				t.Errorf("expected %q but found %q", c.violations, found)
+			}
+		})
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	schema := &Schema{Keys: []KeySchema{{Name: "A", Required: true}}}
+	violations := Validate([]Entry{{Pair: Pair{Key: "B", Val: "1"}, Line: 1}}, schema)
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations but found %v", violations)
+	}
+	if !errors.Is(violations[0], ErrUnknownKey) {
+		t.Errorf("expected %v but found %v", ErrUnknownKey, violations[0])
+	}
+	if !errors.Is(violations[1], ErrMissingRequired) {
+		t.Errorf("expected %v but found %v", ErrMissingRequired, violations[1])
+	}
+}
+
+func TestSchema_Defaults(t *testing.T) {
+	schema := &Schema{Keys: []KeySchema{
+		{Name: "A", Default: "1"},
+		{Name: "B", Default: "2"},
+		{Name: "C", Required: true, Default: "3"},
+		{Name: "D"},
+	}}
+	entries := []Entry{{Pair: Pair{Key: "A", Val: "x"}}, {Pair: Pair{Key: "B", Val: ""}}}
+
+	found := []Pair{}
+	for _, e := range schema.Defaults(entries) {
+		found = append(found, e.Pair)
+	}
+	expected := []Pair{{"A", "x"}, {"B", ""}, {"B", "2"}}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected %v but found %v", expected, found)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"fmt"
+	"os"
+)
+
+// Load parses the named files with ParseFiles and sets every key which is
+// not already present in the process environment, even with an empty value.
+// Keys in later files take precedence over earlier ones. If no files are
+// given .env is loaded.
+//
+// Use LoadWithRestore to undo the changes afterwards, such as in tests.
+func Load(names ...string) error {
+	_, err := load(names, false)
+	return err
+}
+
+// Overload parses the named files with ParseFiles and sets every key in the
+// process environment, replacing existing values. Keys in later files take
+// precedence over earlier ones. If no files are given .env is loaded.
+//
+// Use OverloadWithRestore to undo the changes afterwards, such as in tests.
+func Overload(names ...string) error {
+	_, err := load(names, true)
+	return err
+}
+
+// LoadWithRestore is like Load but also returns a function which undoes
+// every change, as returned by Apply:
+//
+//	restore, err := envparse.LoadWithRestore("testdata/.env")
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer restore()
+func LoadWithRestore(names ...string) (func() error, error) {
+	return load(names, false)
+}
+
+// OverloadWithRestore is like Overload but also returns a function which
+// undoes every change, as returned by Apply.
+func OverloadWithRestore(names ...string) (func() error, error) {
+	return load(names, true)
+}
+
+// load parses the named files and applies them to the process environment.
+func load(names []string, overwrite bool) (func() error, error) {
+	if len(names) == 0 {
+		names = []string{".env"}
+	}
+	pairs, err := ParseFiles(names)
+	if err != nil {
+		return nil, err
+	}
+	return Apply(pairs, overwrite)
+}
+
+// Apply sets each pair in the process environment, skipping keys which are
+// already present unless overwrite is true. The returned restore function
+// undoes every change, setting changed keys back to their previous values
+// and unsetting added keys:
+//
+//	restore, err := envparse.Apply(pairs, true)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer restore()
+//
+// If a key cannot be set the changes made so far are undone and the error is
+// returned.
+func Apply(pairs []Pair, overwrite bool) (func() error, error) {
+	type change struct {
+		key     string
+		prev    string
+		existed bool
+	}
+	changes := []change{}
+	restore := func() error {
+		var first error
+		for i := len(changes) - 1; i >= 0; i-- {
+			c := changes[i]
+			var err error
+			if c.existed {
+				err = os.Setenv(c.key, c.prev)
+			} else {
+				err = os.Unsetenv(c.key)
+			}
+			if err != nil && first == nil {
+				first = err
+			}
+		}
+		changes = nil
+		return first
+	}
+
+	for _, p := range pairs {
+		prev, existed := os.LookupEnv(p.Key)
+		if existed && (!overwrite || prev == p.Val) {
+			continue
+		}
+		if err := os.Setenv(p.Key, p.Val); err != nil {
+			restore()
+			return nil, fmt.Errorf("setting %s: %w", p.Key, err)
+		}
+		changes = append(changes, change{key: p.Key, prev: prev, existed: existed})
+	}
+	return restore, nil
+}
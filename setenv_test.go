@@ -0,0 +1,141 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	os.Setenv("ENVPARSE_EXISTING", "old")
+	os.Setenv("ENVPARSE_EMPTY", "")
+	os.Unsetenv("ENVPARSE_NEW")
+	defer os.Unsetenv("ENVPARSE_EXISTING")
+	defer os.Unsetenv("ENVPARSE_EMPTY")
+
+	pairs := []Pair{
+		{"ENVPARSE_EXISTING", "new"},
+		{"ENVPARSE_EMPTY", "new"},
+		{"ENVPARSE_NEW", "new"},
+	}
+
+	restore, err := Apply(pairs, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := os.Getenv("ENVPARSE_EXISTING"); v != "old" {
+		t.Errorf("expected existing key to be kept but found %q", v)
+	}
+	if v := os.Getenv("ENVPARSE_EMPTY"); v != "" {
+		t.Errorf("expected empty key to be kept but found %q", v)
+	}
+	if v := os.Getenv("ENVPARSE_NEW"); v != "new" {
+		t.Errorf("expected new key to be set but found %q", v)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := os.LookupEnv("ENVPARSE_NEW"); ok {
+		t.Errorf("expected new key to be unset")
+	}
+
+	restore, err = Apply(pairs, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range pairs {
+		if v := os.Getenv(p.Key); v != "new" {
+			t.Errorf("expected %s=new but found %q", p.Key, v)
+		}
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := os.LookupEnv("ENVPARSE_EMPTY"); !ok || v != "" {
+		t.Errorf("expected empty key to be restored but found %q", v)
+	}
+	if v := os.Getenv("ENVPARSE_EXISTING"); v != "old" {
+		t.Errorf("expected existing key to be restored but found %q", v)
+	}
+	if _, ok := os.LookupEnv("ENVPARSE_NEW"); ok {
+		t.Errorf("expected new key to be unset")
+	}
+}
+
+func TestApply_Err(t *testing.T) {
+	os.Unsetenv("ENVPARSE_NEW")
+	_, err := Apply([]Pair{{"ENVPARSE_NEW", "1"}, {"BAD=KEY", "1"}}, true)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, ok := os.LookupEnv("ENVPARSE_NEW"); ok {
+		t.Errorf("expected changes to be undone")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	names, dir := writeFiles(t, "ENVPARSE_A=1\nENVPARSE_B=1\n", "ENVPARSE_B=2\n")
+	defer os.RemoveAll(dir)
+	os.Setenv("ENVPARSE_A", "env")
+	defer os.Unsetenv("ENVPARSE_A")
+	defer os.Unsetenv("ENVPARSE_B")
+
+	if err := Load(names...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a, b := os.Getenv("ENVPARSE_A"), os.Getenv("ENVPARSE_B"); a != "env" || b != "2" {
+		t.Errorf("expected env and 2 but found %q and %q", a, b)
+	}
+
+	if err := Overload(names...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := os.Getenv("ENVPARSE_A"); a != "1" {
+		t.Errorf("expected 1 but found %q", a)
+	}
+
+	if err := Load(names[0] + ".missing"); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestLoadWithRestore(t *testing.T) {
+	names, dir := writeFiles(t, "ENVPARSE_A=1\nENVPARSE_B=1\n")
+	defer os.RemoveAll(dir)
+	os.Setenv("ENVPARSE_A", "env")
+	defer os.Unsetenv("ENVPARSE_A")
+
+	restore, err := LoadWithRestore(names...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a, b := os.Getenv("ENVPARSE_A"), os.Getenv("ENVPARSE_B"); a != "env" || b != "1" {
+		t.Errorf("expected env and 1 but found %q and %q", a, b)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := os.LookupEnv("ENVPARSE_B"); ok {
+		t.Errorf("expected ENVPARSE_B to be unset")
+	}
+
+	restore, err = OverloadWithRestore(names...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := os.Getenv("ENVPARSE_A"); a != "1" {
+		t.Errorf("expected 1 but found %q", a)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := os.Getenv("ENVPARSE_A"); a != "env" {
+		t.Errorf("expected env but found %q", a)
+	}
+
+	if _, err := OverloadWithRestore(names[0] + ".missing"); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
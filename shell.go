@@ -0,0 +1,142 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Shell selects the syntax written by EncodeShell.
+type Shell uint8
+
+const (
+	// ShellPOSIX writes export KEY='value' lines for sh, bash, and zsh.
+	ShellPOSIX Shell = iota
+
+	// ShellFish writes set -gx KEY 'value' lines for fish.
+	ShellFish
+
+	// ShellPowerShell writes $env:KEY = 'value' lines for PowerShell. Note
+	// that PowerShell removes variables which are set to an empty value.
+	ShellPowerShell
+
+	// ShellCmd writes set "KEY=value" lines for cmd batch files. Values
+	// containing newlines cannot be represented and are an error.
+	ShellCmd
+)
+
+// EncodeShell writes a command setting each pair as an exported environment
+// variable in the given shell. Values are quoted so that they are set
+// exactly, without expanding variables or other special characters.
+//
+// An error is returned without writing anything if any key is not a valid
+// shell variable name, such as keys containing the . and / characters Parse
+// allows, or if a value cannot be represented in the shell. Values may never
+// contain NUL characters.
+func EncodeShell(w io.Writer, pairs []Pair, sh Shell) error {
+	for _, p := range pairs {
+		if _, err := checkShellKeyAt([]byte(p.Key)); err != nil {
+			return fmt.Errorf("invalid key %q: %w", p.Key, err)
+		}
+		if err := checkShellValue(p.Val, sh); err != nil {
+			return fmt.Errorf("invalid value for key %q: %w", p.Key, err)
+		}
+	}
+
+	buf := []byte{}
+	for _, p := range pairs {
+		buf = appendShellPair(buf[:0], p, sh)
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkShellValue returns an error if v cannot be set in the shell.
+func checkShellValue(v string, sh Shell) error {
+	if strings.IndexByte(v, 0) >= 0 {
+		return fmt.Errorf("NUL characters cannot be exported")
+	}
+	if sh == ShellCmd && strings.ContainsAny(v, "\r\n") {
+		return fmt.Errorf("newlines cannot be exported to cmd")
+	}
+	return nil
+}
+
+// appendShellPair appends the command setting p in the shell to buf.
+func appendShellPair(buf []byte, p Pair, sh Shell) []byte {
+	switch sh {
+	case ShellFish:
+		buf = append(buf, "set -gx "...)
+		buf = append(buf, p.Key...)
+		buf = append(buf, ' ', '\'')
+		for i := 0; i < len(p.Val); i++ {
+			// Only backslashes and single quotes are escaped in fish
+			if c := p.Val[i]; c == '\\' || c == '\'' {
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, p.Val[i])
+		}
+		buf = append(buf, '\'')
+	case ShellPowerShell:
+		buf = append(buf, "$env:"...)
+		buf = append(buf, p.Key...)
+		buf = append(buf, " = '"...)
+		for i := 0; i < len(p.Val); i++ {
+			// PowerShell treats the typographic quotes U+2018 to U+201B like
+			// single quotes, so every kind is doubled
+			if strings.HasPrefix(p.Val[i:], "\u2018") || strings.HasPrefix(p.Val[i:], "\u2019") ||
+				strings.HasPrefix(p.Val[i:], "\u201a") || strings.HasPrefix(p.Val[i:], "\u201b") {
+				buf = append(buf, p.Val[i:i+3]...)
+				buf = append(buf, p.Val[i:i+3]...)
+				i += 2
+				continue
+			}
+			if p.Val[i] == '\'' {
+				buf = append(buf, '\'')
+			}
+			buf = append(buf, p.Val[i])
+		}
+		buf = append(buf, '\'')
+	case ShellCmd:
+		buf = append(buf, "set \""...)
+		buf = append(buf, p.Key...)
+		buf = append(buf, '=')
+
+		// Special characters must be escaped with ^ whenever a double quote
+		// within the value leaves them outside of quotes
+		quoted := true
+		for i := 0; i < len(p.Val); i++ {
+			switch c := p.Val[i]; c {
+			case '"':
+				quoted = !quoted
+			case '%':
+				buf = append(buf, '%')
+			case '^', '&', '|', '<', '>', '(', ')':
+				if !quoted {
+					buf = append(buf, '^')
+				}
+			}
+			buf = append(buf, p.Val[i])
+		}
+		buf = append(buf, '"')
+	default:
+		buf = append(buf, "export "...)
+		buf = append(buf, p.Key...)
+		buf = append(buf, '=', '\'')
+		for i := 0; i < len(p.Val); i++ {
+			// Close the quotes, escape the single quote, and reopen them
+			if p.Val[i] == '\'' {
+				buf = append(buf, `'\''`...)
+				continue
+			}
+			buf = append(buf, p.Val[i])
+		}
+		buf = append(buf, '\'')
+	}
+	return append(buf, '\n')
+}
@@ -0,0 +1,99 @@
+// Copyright IBM Corp. 2017, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package envparse
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeShell(t *testing.T) {
+	pairs := []Pair{
+		{"A", "simple"},
+		{"B", "it's $HOME"},
+		{"C", "a\\b\nc"},
+		{"D", "’%x%\" & \"|"},
+		{"E", ""},
+	}
+
+	cases := []struct {
+		name  string
+		shell Shell
+		out   string
+	}{
+		{"POSIX", ShellPOSIX, "export A='simple'\n" +
+			"export B='it'\\''s $HOME'\n" +
+			"export C='a\\b\nc'\n" +
+			"export D='’%x%\" & \"|'\n" +
+			"export E=''\n"},
+		{"Fish", ShellFish, "set -gx A 'simple'\n" +
+			"set -gx B 'it\\'s $HOME'\n" +
+			"set -gx C 'a\\\\b\nc'\n" +
+			"set -gx D '’%x%\" & \"|'\n" +
+			"set -gx E ''\n"},
+		{"PowerShell", ShellPowerShell, "$env:A = 'simple'\n" +
+			"$env:B = 'it''s $HOME'\n" +
+			"$env:C = 'a\\b\nc'\n" +
+			"$env:D = '’’%x%\" & \"|'\n" +
+			"$env:E = ''\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := EncodeShell(&buf, pairs, c.shell); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != c.out {
+				t.Errorf("expected:\n%s\nbut found:\n%s", c.out, buf.String())
+			}
+		})
+	}
+}
+
+func TestEncodeShell_Cmd(t *testing.T) {
+	pairs := []Pair{
+		{"A", "simple"},
+		{"B", "100% & more"},
+		{"C", `say "a&b" ^ (c)`},
+	}
+	expected := "set \"A=simple\"\n" +
+		"set \"B=100%% & more\"\n" +
+		"set \"C=say \"a^&b\" ^ (c)\"\n"
+
+	buf := bytes.Buffer{}
+	if err := EncodeShell(&buf, pairs, ShellCmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\nbut found:\n%s", expected, buf.String())
+	}
+}
+
+func TestEncodeShell_Invalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		pair  Pair
+		shell Shell
+	}{
+		{"DottedKey", Pair{"A.B", "1"}, ShellPOSIX},
+		{"SlashKey", Pair{"A/B", "1"}, ShellFish},
+		{"EmptyKey", Pair{"", "1"}, ShellPowerShell},
+		{"NUL", Pair{"A", "a\x00b"}, ShellPOSIX},
+		{"CmdNewline", Pair{"A", "a\nb"}, ShellCmd},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := EncodeShell(&buf, []Pair{{"OK", "1"}, c.pair}, c.shell)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expected nothing to be written but found %q", buf.String())
+			}
+		})
+	}
+}